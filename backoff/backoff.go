@@ -20,8 +20,16 @@ func NewManager(cfg *config.Config) *Manager {
 }
 
 // HandleTMR is called when a "Too Many Requests" error occurs.
-// It sleeps for 20s on the first TMR, and 40s on subsequent consecutive TMRs.
+// It sleeps for the duration returned by Next.
 func (m *Manager) HandleTMR() {
+	sleepDuration := m.Next()
+	log.Printf("Backoff activated, sleeping for %v.", sleepDuration)
+	time.Sleep(sleepDuration)
+}
+
+// Next records a "Too Many Requests" error and returns how long to back off:
+// 20s on the first TMR, and 40s on subsequent consecutive TMRs.
+func (m *Manager) Next() time.Duration {
 	var sleepDuration time.Duration
 
 	if m.lastWasTMR {
@@ -32,11 +40,9 @@ func (m *Manager) HandleTMR() {
 		sleepDuration = 20 * time.Second
 	}
 
-	log.Printf("Backoff activated, sleeping for %v.", sleepDuration)
-	time.Sleep(sleepDuration)
-
 	// Set the state for the next potential error.
 	m.lastWasTMR = true
+	return sleepDuration
 }
 
 // Reset clears the backoff state, ensuring the next TMR uses the initial 20s wait.
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// backupWaitAttempts bounds how many times a boot volume backup is polled
+// while waiting for it to become AVAILABLE. Each poll is paced by the client.
+const backupWaitAttempts = 90
+
+// runBackup implements the backup subcommand: it takes a boot volume backup
+// of an instance, for use with OCI_BOOT_VOLUME_BACKUP_ID.
+func runBackup(client *oci.Client, cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	name := flags.String("name", "", "Display name of the backup (default: <instance>-backup-<time>)")
+	backupType := flags.String("type", "", "FULL or INCREMENTAL (default: chosen by OCI)")
+	wait := flags.Bool("wait", true, "Wait for the backup to become AVAILABLE")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: oahc-go backup [flags] <instance name or OCID>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("backup takes exactly one instance")
+	}
+
+	*backupType = strings.ToUpper(*backupType)
+	if *backupType != "" && *backupType != "FULL" && *backupType != "INCREMENTAL" {
+		return fmt.Errorf("-type must be FULL or INCREMENTAL")
+	}
+
+	instance, err := findInstance(client, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	attachments, err := client.ListBootVolumeAttachments(instance.AvailabilityDomain, instance.CompartmentID, instance.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list boot volume attachments: %w", err)
+	}
+	var bootVolumeID string
+	for _, attachment := range attachments {
+		if attachment.LifecycleState == "ATTACHED" {
+			bootVolumeID = attachment.BootVolumeID
+			break
+		}
+	}
+	if bootVolumeID == "" {
+		return fmt.Errorf("instance %s has no attached boot volume", instance.DisplayName)
+	}
+
+	if *name == "" {
+		*name = fmt.Sprintf("%s-backup-%s", instance.DisplayName, time.Now().Format("20060102-1504"))
+	}
+	backup, err := client.CreateBootVolumeBackup(bootVolumeID, *name, *backupType)
+	if err != nil {
+		return fmt.Errorf("failed to create boot volume backup: %w", err)
+	}
+	log.Printf("Backing up boot volume %s of %s as %s (%s).", bootVolumeID, instance.DisplayName, backup.DisplayName, backup.ID)
+
+	if *wait {
+		for i := 0; backup.LifecycleState != "AVAILABLE"; i++ {
+			if backup.LifecycleState == "FAULTY" || backup.LifecycleState == "TERMINATED" {
+				return fmt.Errorf("boot volume backup %s is %s", backup.ID, backup.LifecycleState)
+			}
+			if i == backupWaitAttempts {
+				return fmt.Errorf("boot volume backup %s did not become AVAILABLE (still %s)", backup.ID, backup.LifecycleState)
+			}
+			if backup, err = client.GetBootVolumeBackup(backup.ID); err != nil {
+				return fmt.Errorf("failed to get boot volume backup: %w", err)
+			}
+		}
+		log.Printf("Boot volume backup %s is AVAILABLE.", backup.ID)
+	}
+
+	log.Printf("To launch from this backup in any availability domain, set OCI_BOOT_VOLUME_BACKUP_ID=%s", backup.ID)
+	return nil
+}
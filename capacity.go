@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// placement is one size and fault domain combination to try within an
+// availability domain. An empty fault domain lets OCI choose.
+type placement struct {
+	size        config.ShapeSize
+	faultDomain string
+}
+
+// placements returns the combinations to try in an availability domain, in
+// preference order: each size is tried in every configured fault domain
+// before stepping down to the next size.
+func (f *finder) placements(sizes []config.ShapeSize) []placement {
+	faultDomains := f.cfg.FaultDomains
+	if len(faultDomains) == 0 {
+		faultDomains = []string{""}
+	}
+
+	var result []placement
+	for _, size := range sizes {
+		for _, fd := range faultDomains {
+			result = append(result, placement{size: size, faultDomain: fd})
+		}
+	}
+	return result
+}
+
+// label describes the placement in log lines and status output.
+func (p placement) label(ad string, multiple bool) string {
+	switch {
+	case !multiple:
+		return ad
+	case p.faultDomain == "":
+		return fmt.Sprintf("%s (%s)", ad, p.size)
+	default:
+		return fmt.Sprintf("%s (%s, %s)", ad, p.size, p.faultDomain)
+	}
+}
+
+// shapeConfigTolerance is how far a reported OCPU or memory value may be
+// from the requested one and still match it. Reports echo the requested
+// sizes, but as floats that may come back rounded.
+const shapeConfigTolerance = 0.01
+
+// reportedPlacements creates a compute capacity report for placements in ad
+// and returns those reported as AVAILABLE, keeping their preference order.
+// Placements the report has no entry for are kept, so that a launch attempt
+// decides them instead.
+func (f *finder) reportedPlacements(ad string, placements []placement) ([]placement, error) {
+	checks := make([]oci.CreateCapacityReportShapeAvailabilityDetails, len(placements))
+	for i, p := range placements {
+		shapeConfig := shapeConfigOf(p.size)
+		checks[i] = oci.CreateCapacityReportShapeAvailabilityDetails{
+			InstanceShapeConfig: &shapeConfig,
+			FaultDomain:         p.faultDomain,
+		}
+	}
+
+	report, err := f.client.CreateComputeCapacityReport(ad, checks)
+	if err != nil {
+		return nil, err
+	}
+
+	var available []placement
+	for i, p := range placements {
+		result, ok := reportEntry(report.ShapeAvailabilities, *checks[i].InstanceShapeConfig, p.faultDomain)
+		if !ok {
+			log.Printf("Checking %s: capacity report has no entry, trying to launch.", p.label(ad, true))
+			available = append(available, p)
+			continue
+		}
+		log.Printf("Checking %s: capacity report says %s (available count %d).", p.label(ad, true), result.AvailabilityStatus, result.AvailableCount)
+		if result.AvailabilityStatus == "AVAILABLE" {
+			available = append(available, p)
+		}
+	}
+	return available, nil
+}
+
+// reportEntry finds the report entry for a shape config and fault domain.
+// An empty fault domain matches an entry for any fault domain.
+func reportEntry(results []oci.CapacityReportShapeAvailability, shapeConfig oci.ShapeConfig, faultDomain string) (oci.CapacityReportShapeAvailability, bool) {
+	for _, result := range results {
+		if result.InstanceShapeConfig == nil {
+			continue
+		}
+		if math.Abs(float64(result.InstanceShapeConfig.Ocpus-shapeConfig.Ocpus)) > shapeConfigTolerance ||
+			math.Abs(float64(result.InstanceShapeConfig.MemoryInGBs-shapeConfig.MemoryInGBs)) > shapeConfigTolerance {
+			continue
+		}
+		if faultDomain != "" && result.FaultDomain != faultDomain {
+			continue
+		}
+		return result, true
+	}
+	return oci.CapacityReportShapeAvailability{}, false
+}
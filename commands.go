@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// commands are the subcommands that run instead of the capacity finder.
+var commands = map[string]func(client *oci.Client, cfg *config.Config, args []string) error{
+	"backup":    runBackup,
+	"start":     instanceActionCommand("start", "START"),
+	"stop":      instanceActionCommand("stop", "STOP"),
+	"softstop":  instanceActionCommand("softstop", "SOFTSTOP"),
+	"reboot":    instanceActionCommand("reboot", "SOFTRESET"),
+	"terminate": runTerminate,
+}
+
+// runCommand loads the configuration of the named target and runs a
+// subcommand. Subcommands only need the OCI credentials, not the full launch
+// configuration.
+func runCommand(configFile, envFile, target string, args []string) error {
+	command, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	cfg, err := selectTarget(configFile, envFile, target)
+	if err != nil {
+		return err
+	}
+	if err := cfg.ValidateCredentials(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	signer, err := oci.NewSigner(cfg.TenancyID, cfg.UserID, cfg.KeyFingerprint, cfg.PrivateKeyPath)
+	if err != nil {
+		return fmt.Errorf("failed to create OCI signer: %w", err)
+	}
+	client := oci.NewClient(cfg, signer)
+	if err := resolveCompartment(client, cfg); err != nil {
+		return fmt.Errorf("failed to resolve OCI_COMPARTMENT_ID: %w", err)
+	}
+
+	return command(client, cfg, args[1:])
+}
+
+// selectTarget loads the configuration of the named target. The name may be
+// omitted when there is a single target.
+func selectTarget(configFile, envFile, name string) (*config.Config, error) {
+	targets, err := config.LoadTargets(configFile, envFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if name == "" {
+		if len(targets) > 1 {
+			return nil, fmt.Errorf("configuration defines %d targets, select one with -target", len(targets))
+		}
+		return targets[0], nil
+	}
+
+	var names []string
+	for _, cfg := range targets {
+		if cfg.Name == name {
+			return cfg, nil
+		}
+		names = append(names, cfg.Name)
+	}
+	return nil, fmt.Errorf("unknown target %q (defined: %s)", name, strings.Join(names, ", "))
+}
+
+// selectInstances returns the non-terminated instances matching any of refs
+// (an OCID or display name) or, if tag is set, carrying that freeform
+// key=value tag. Each ref must match at least one instance.
+func selectInstances(client *oci.Client, refs []string, tag string) ([]oci.Instance, error) {
+	var tagKey, tagValue string
+	if tag != "" {
+		var ok bool
+		if tagKey, tagValue, ok = strings.Cut(tag, "="); !ok || tagKey == "" {
+			return nil, fmt.Errorf("tag %q is not in the form key=value", tag)
+		}
+	}
+	if len(refs) == 0 && tag == "" {
+		return nil, fmt.Errorf("no instances selected, pass instance names, OCIDs or -tag")
+	}
+
+	instances, err := client.ListInstances()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	matched := make(map[string]bool)
+	var selected []oci.Instance
+	for _, instance := range instances {
+		if instance.LifecycleState == "TERMINATED" {
+			continue
+		}
+		isSelected := false
+		for _, ref := range refs {
+			if instance.ID == ref || instance.DisplayName == ref {
+				matched[ref] = true
+				isSelected = true
+			}
+		}
+		if tag != "" {
+			if val, ok := instance.FreeformTags[tagKey]; ok && val == tagValue {
+				isSelected = true
+			}
+		}
+		if isSelected {
+			selected = append(selected, instance)
+		}
+	}
+
+	for _, ref := range refs {
+		if !matched[ref] {
+			return nil, fmt.Errorf("no instance named %q", ref)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no instance has the tag %q", tag)
+	}
+	return selected, nil
+}
+
+// findInstance returns the single non-terminated instance whose OCID or
+// display name is ref.
+func findInstance(client *oci.Client, ref string) (*oci.Instance, error) {
+	instances, err := selectInstances(client, []string{ref}, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) > 1 {
+		return nil, fmt.Errorf("%d instances are named %q, use the OCID instead", len(instances), ref)
+	}
+	return &instances[0], nil
+}
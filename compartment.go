@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// resolveCompartment turns OCI_COMPARTMENT_ID into an OCID. A value that is
+// not an OCID is treated as a slash-separated path of compartment names below
+// the tenancy root, e.g. "dev/sandbox".
+func resolveCompartment(client *oci.Client, cfg *config.Config) error {
+	if cfg.CompartmentID == "" || strings.HasPrefix(cfg.CompartmentID, "ocid1.") {
+		return nil
+	}
+
+	path := cfg.CompartmentID
+	parentID := cfg.TenancyID
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		compartments, err := client.ListCompartments(parentID, name)
+		if err != nil {
+			return fmt.Errorf("failed to look up compartment %q: %w", name, err)
+		}
+		if len(compartments) == 0 {
+			return fmt.Errorf("compartment %q not found in path %q", name, path)
+		}
+		parentID = compartments[0].ID
+	}
+
+	log.Printf("Resolved compartment %q to %s.", path, parentID)
+	cfg.CompartmentID = parentID
+	return nil
+}
+
+// compartmentSubtree returns rootID and the OCIDs of all active compartments below it.
+func compartmentSubtree(client *oci.Client, rootID string) ([]string, error) {
+	ids := []string{rootID}
+	for i := 0; i < len(ids); i++ {
+		children, err := client.ListCompartments(ids[i], "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list child compartments of %s: %w", ids[i], err)
+		}
+		for _, child := range children {
+			ids = append(ids, child.ID)
+		}
+	}
+	return ids, nil
+}
+
+// listInstances lists instances in every scanned region, remembering which
+// region each one is in.
+func (f *finder) listInstances() ([]oci.Instance, error) {
+	defer f.use(f.regions[0])
+
+	var instances []oci.Instance
+	for _, r := range f.regions {
+		f.use(r)
+		found, err := f.listRegionInstances()
+		if err != nil {
+			if len(f.regions) > 1 {
+				err = fmt.Errorf("%s: %w", r.name, err)
+			}
+			return nil, err
+		}
+		for _, instance := range found {
+			f.instanceRegions[instance.ID] = r
+		}
+		instances = append(instances, found...)
+	}
+	return instances, nil
+}
+
+// listRegionInstances lists instances in the launch compartment of the
+// current region, or across its whole subtree with OCI_COUNT_SUBTREE. This is
+// the scope counted toward OCI_MAX_INSTANCES; the free-tier budget always
+// covers the whole tenancy (see freeTierInstances).
+func (f *finder) listRegionInstances() ([]oci.Instance, error) {
+	if !f.cfg.CountSubtree {
+		return f.client.ListInstances()
+	}
+
+	if f.compartments == nil {
+		compartments, err := compartmentSubtree(f.client, f.cfg.LaunchCompartmentID())
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Counting instances across %d compartment(s).", len(compartments))
+		f.compartments = compartments
+	}
+
+	var instances []oci.Instance
+	for _, compartmentID := range f.compartments {
+		found, err := f.client.ListInstancesInCompartment(compartmentID)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, found...)
+	}
+	return instances, nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AgentPlugin is the desired state of one Oracle Cloud Agent plugin.
+type AgentPlugin struct {
+	Name         string
+	DesiredState string // ENABLED or DISABLED
+}
+
+// HasAgentConfig reports whether any Oracle Cloud Agent setting is configured.
+func (c *Config) HasAgentConfig() bool {
+	return c.AgentMonitoringDisabled || c.AgentManagementDisabled || c.AgentAllPluginsDisabled || len(c.AgentPlugins) > 0
+}
+
+// agentPlugins converts "name=state" pairs, e.g. "Bastion=ENABLED" from
+// "Bastion=ENABLED,Vulnerability Scanning=DISABLED", to plugin settings.
+// Order is preserved.
+func agentPlugins(pairs []keyValue) ([]AgentPlugin, error) {
+	var plugins []AgentPlugin
+	seen := make(map[string]bool)
+	for _, pair := range pairs {
+		name := strings.TrimSpace(pair.key)
+		state := strings.ToUpper(strings.TrimSpace(pair.value))
+		if state != "ENABLED" && state != "DISABLED" {
+			return nil, fmt.Errorf("plugin %q: state must be ENABLED or DISABLED, got %q", name, state)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("plugin %q is listed more than once", name)
+		}
+		seen[name] = true
+		plugins = append(plugins, AgentPlugin{Name: name, DesiredState: state})
+	}
+	return plugins, nil
+}
+
+// validateAgent checks that the plugin list does not contradict the global switches.
+func (c *Config) validateAgent() error {
+	if !c.AgentAllPluginsDisabled {
+		return nil
+	}
+	var errs []error
+	for _, plugin := range c.AgentPlugins {
+		if plugin.DesiredState == "ENABLED" {
+			errs = append(errs, fmt.Errorf("OCI_AGENT_PLUGINS enables %q but OCI_AGENT_ALL_PLUGINS_DISABLED is set", plugin.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
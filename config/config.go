@@ -2,63 +2,383 @@ package config
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 // Config holds all configuration for the application.
 type Config struct {
+	Name string // Target name from the [name] section, empty for a single target
+
 	// OCI General
 	Region         string
 	UserID         string
 	TenancyID      string
 	KeyFingerprint string
 	PrivateKeyPath string
+	Regions        []RegionConfig // Optional, additional regions to scan
+	CompartmentID  string         // Optional; an OCID or a name path, resolved to an OCID at startup
+	CountSubtree   bool
 
 	// Instance Parameters
-	AvailabilityDomain string
-	SubnetID           string
-	ImageID            string
-	Shape              string
-	OCPUs              int
-	MemoryInGBs        int
-	SSHKey             string
-	MaxInstances       int
-	BootVolumeSizeGbs  int    // Optional
-	BootVolumeID       string // Optional
+	AvailabilityDomain      string
+	SubnetID                string
+	ImageID                 string
+	ImageOS                 string // Optional, resolves the image by operating system instead of ImageID
+	ImageOSVersion          string // Optional
+	ImageRefreshHours       int
+	InstanceConfigurationID string // Optional, launches from an OCI instance configuration
+	Shape                   string
+	OCPUs                   int
+	MemoryInGBs             int
+	SSHKey                  string                 // One or more public keys, newline-separated
+	UserData                string                 // Optional, base64-encoded cloud-init
+	Metadata                map[string]string      // Optional extra metadata
+	ExtendedMetadata        map[string]interface{} // Optional
+	MaxInstances            int
+	BootVolumeSizeGbs       int    // Optional
+	BootVolumeID            string // Optional
+	BootVolumeBackupID      string // Optional, restored into the AD being tried
+	AssignPublicIP          bool   // Assign an ephemeral public IP
+	ReservedPublicIPID      string // Optional
+	AssignIpv6              bool
+	NsgIDs                  []string // Optional
+	PrivateIP               string   // Optional
+	HostnameLabel           string   // Optional
+	VnicDisplayName         string   // Optional
+	SkipSourceDestCheck     bool
+	FreeformTags            map[string]string            // Optional, values may be templates
+	DefinedTags             map[string]map[string]string // Optional, namespace -> key -> template
+	CountTagKey             string                       // Optional
+	CountTagValue           string
+	FaultDomains            []string // Optional, tried in order within each AD
+	LaunchFirmware          string   // Optional
+	LaunchNetworkType       string   // Optional
+	LaunchBootVolumeType    string   // Optional
+	PvEncryptionInTransit   bool
+	PlatformType            string // Optional, required for Shielded Instances
+	SecureBoot              bool
+	MeasuredBoot            bool
+	TrustedPlatformModule   bool
+	LegacyIMDSDisabled      bool
+	RecoveryAction          string // Optional
+	LiveMigrationPreferred  *bool  // Optional
+	AgentMonitoringDisabled bool
+	AgentManagementDisabled bool
+	AgentAllPluginsDisabled bool
+	AgentPlugins            []AgentPlugin // Optional, desired state per Cloud Agent plugin
+	BlockVolumes            []BlockVolume // Optional, attached once the instance is RUNNING
+	ShapeFallbacks          []ShapeSize
+	AutoSize                bool // Shrink launches to the remaining free-tier budget
+	AllowPaid               bool // Permit launches beyond the free tier
+	ScanStrategy            string
+	CapacityReservationID   string // Optional
+	CapacityReservationMode bool
 
 	// Notifications
-	TelegramBotAPIKey string
-	TelegramUserID    string
+	TelegramBotAPIKey  string
+	TelegramUserID     string
+	TelegramAPIURL     string // Optional
+	TelegramBotEnabled bool
 
 	// App behavior
-	BackoffInitialSeconds int
-	BackoffMaxSeconds     int
-	JSONLogPath           string // Optional
+	BackoffInitialSeconds      int
+	BackoffMaxSeconds          int
+	JSONLogPath                string // Optional
+	WatchMode                  bool
+	WatchIntervalSeconds       int
+	ResizeIntervalSeconds      int // Optional
+	LimitsCheckIntervalSeconds int
+	KeepAliveCheckHours        int // Optional, 0 disables idle reclamation checks
+	KeepAliveWarnPercent       int
+
+	sources map[string]string // Where each setting was set, e.g. ".env:12"
 }
 
-// Load reads configuration from a .env file and environment variables.
-func Load(path string) (*Config, error) {
-	cfg := &Config{}
-	defaults(cfg)
+// Scan strategies for OCI_SCAN_STRATEGY.
+const (
+	// ScanStrategyLaunch tries LaunchInstance directly in every availability domain.
+	ScanStrategyLaunch = "launch"
+	// ScanStrategyReport checks a compute capacity report first and only
+	// launches where capacity is reported as available.
+	ScanStrategyReport = "report"
+)
+
+// ShapeSize is an OCPU/memory combination for a flexible shape.
+type ShapeSize struct {
+	OCPUs       int
+	MemoryInGBs int
+}
+
+func (s ShapeSize) String() string {
+	return fmt.Sprintf("%d OCPU/%d GB", s.OCPUs, s.MemoryInGBs)
+}
+
+// LaunchCompartmentID returns the compartment instances are launched and
+// counted in: OCI_COMPARTMENT_ID if set, otherwise the tenancy root.
+func (c *Config) LaunchCompartmentID() string {
+	if c.CompartmentID != "" {
+		return c.CompartmentID
+	}
+	return c.TenancyID
+}
+
+// ShapeSizes returns the preferred size followed by the configured fallbacks,
+// in the order they should be tried, without duplicates.
+func (c *Config) ShapeSizes() []ShapeSize {
+	sizes := []ShapeSize{{OCPUs: c.OCPUs, MemoryInGBs: c.MemoryInGBs}}
+	for _, size := range c.ShapeFallbacks {
+		if !slices.Contains(sizes, size) {
+			sizes = append(sizes, size)
+		}
+	}
+	return sizes
+}
 
-	envMap, err := readEnvFile(path)
+// Load reads configuration from a TOML config file, a .env file and
+// environment variables. The files must not define several targets; use
+// LoadTargets for that.
+func Load(configPath, envPath string) (*Config, error) {
+	targets, err := LoadTargets(configPath, envPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) > 1 {
+		return nil, fmt.Errorf("configuration defines %d targets, select one", len(targets))
+	}
+	return targets[0], nil
+}
+
+// LoadTargets reads one configuration per target. Settings of a target
+// override shared ones; within each, the .env file overrides environment
+// variables, which override the TOML config file. Either file may be missing. If settings have invalid values,
+// the targets are still returned along with the error, with those settings
+// left at their defaults, so that Validate can report the other problems.
+//
+// In the .env file, keys before the first [name] header are shared by all
+// targets and each [name] section starts a target that overrides them. In
+// the config file, top-level settings are shared and each [targets.<name>]
+// table overrides them. Without any sections there is a single, unnamed
+// target.
+func LoadTargets(configPath, envPath string) ([]*Config, error) {
+	fileShared, fileSections, err := readConfigFile(configPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		fileShared = make(map[string]sourcedValue)
+	}
+
+	envShared, envSections, err := readEnvFile(envPath)
 	if err != nil {
 		if !os.IsNotExist(err) {
-			return nil, fmt.Errorf("error reading env file %s: %w", path, err)
+			return nil, fmt.Errorf("error reading env file %s: %w", envPath, err)
 		}
 		// It's okay if the file doesn't exist, we'll rely on environment variables.
-		envMap = make(map[string]string)
+		envShared = make(map[string]sourcedValue)
+	}
+	warnUnknownKeys(envShared, envSections)
+
+	lookup := func(fileTarget, envTarget map[string]sourcedValue) func(string) sourcedValue {
+		return func(key string) sourcedValue {
+			if val, ok := envTarget[key]; ok {
+				return val
+			}
+			if val, ok := fileTarget[key]; ok {
+				return val
+			}
+			if val, ok := envShared[key]; ok {
+				return val
+			}
+			if val, ok := os.LookupEnv(key); ok {
+				return sourcedValue{value: val, source: "environment"}
+			}
+			return fileShared[key]
+		}
+	}
+
+	if len(fileSections) == 0 && len(envSections) == 0 {
+		cfg, err := load(lookup(nil, nil))
+		return []*Config{cfg}, err
+	}
+
+	// Targets are those of the config file followed by any only in the .env file.
+	sections := make(map[string][2]map[string]sourcedValue)
+	var names []string
+	for _, section := range fileSections {
+		sections[section.name] = [2]map[string]sourcedValue{section.values, nil}
+		names = append(names, section.name)
+	}
+	for _, section := range envSections {
+		if _, ok := sections[section.name]; !ok {
+			names = append(names, section.name)
+		}
+		sections[section.name] = [2]map[string]sourcedValue{sections[section.name][0], section.values}
+	}
+
+	var targets []*Config
+	var errs []error
+	for _, name := range names {
+		cfg, err := load(lookup(sections[name][0], sections[name][1]))
+		if err != nil {
+			errs = append(errs, PrefixErrors("target "+name, err))
+		}
+		cfg.Name = name
+		targets = append(targets, cfg)
+	}
+	return targets, errors.Join(errs...)
+}
+
+// ValidateTargets checks settings that must agree between targets. A single
+// Telegram bot answers for all targets, so every target that enables it must
+// use the same bot and user.
+func ValidateTargets(targets []*Config) error {
+	var first *Config
+	for _, c := range targets {
+		if !c.TelegramBotEnabled {
+			continue
+		}
+		if first == nil {
+			first = c
+			continue
+		}
+		if c.TelegramBotAPIKey != first.TelegramBotAPIKey || c.TelegramUserID != first.TelegramUserID || c.TelegramAPIURL != first.TelegramAPIURL {
+			return fmt.Errorf("targets %s and %s both set TELEGRAM_BOT_ENABLED with different TELEGRAM_BOT_API_KEY, TELEGRAM_USER_ID or TELEGRAM_API_URL; one bot answers for all targets", first.Name, c.Name)
+		}
+	}
+	return nil
+}
+
+// PrefixErrors prefixes err, or each error joined in it, with prefix.
+func PrefixErrors(prefix string, err error) error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return fmt.Errorf("%s: %w", prefix, err)
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, PrefixErrors(prefix, e))
+	}
+	return errors.Join(errs...)
+}
+
+// withSources appends to err, or each error joined in it, where the settings
+// named in its message were set.
+func (c *Config) withSources(err error) error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, c.withSources(e))
+		}
+		return errors.Join(errs...)
 	}
 
-	// Helper to get value from file map or fallback to environment variable
+	var sources []string
+	seen := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(err.Error(), func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+	}) {
+		if source, ok := c.sources[word]; ok && !seen[word] {
+			seen[word] = true
+			sources = append(sources, word+" from "+source)
+		}
+	}
+	if len(sources) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w (%s)", err, strings.Join(sources, ", "))
+}
+
+// load builds a configuration from the settings returned by lookup. All
+// invalid values are reported together, each naming its key and source. The
+// configuration is returned even then, with the invalid settings unset.
+func load(lookup func(key string) sourcedValue) (*Config, error) {
+	cfg := &Config{sources: make(map[string]string)}
+	defaults(cfg)
+	var errs []error
+
+	get := func(key string) sourcedValue {
+		val := lookup(key)
+		if val.source != "" {
+			cfg.sources[key] = val.source
+		}
+		return val
+	}
 	getValue := func(key string) string {
-		if val, ok := envMap[key]; ok {
-			return val
+		return get(key).value
+	}
+	// invalid records an error in the value of key.
+	invalid := func(key string, err error) {
+		errs = append(errs, fmt.Errorf("%s (%s): %w", key, lookup(key).source, err))
+	}
+	parseBool := func(key string, dst *bool) {
+		val := get(key)
+		if b, ok := val.parsed.(bool); ok {
+			*dst = b
+			return
+		}
+		if val := val.value; val != "" {
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				invalid(key, fmt.Errorf("%q is not true or false", val))
+				return
+			}
+			*dst = b
+		}
+	}
+	parseInt := func(key string, dst *int) {
+		val := get(key)
+		if n, ok := val.parsed.(int); ok {
+			*dst = n
+			return
+		}
+		if val := val.value; val != "" {
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				invalid(key, fmt.Errorf("%q is not an integer", val))
+				return
+			}
+			*dst = n
 		}
-		return os.Getenv(key)
+	}
+
+	// getList returns a list setting, comma-separated outside config files.
+	getList := func(key string) []string {
+		val := get(key)
+		if items, ok := val.parsed.([]string); ok {
+			return items
+		}
+		return splitList(val.value)
+	}
+	// getPairs returns a key=value setting, comma-separated outside config files.
+	getPairs := func(key string) []keyValue {
+		val := get(key)
+		if pairs, ok := val.parsed.([]keyValue); ok {
+			return pairs
+		}
+		pairs, err := parsePairs(val.value)
+		if err != nil {
+			invalid(key, err)
+		}
+		return pairs
+	}
+	// parseJSON decodes a JSON setting; config files give it as tables instead.
+	parseJSON := func(key string, dst any, what string) bool {
+		if val := getValue(key); val != "" {
+			if err := json.Unmarshal([]byte(val), dst); err != nil {
+				invalid(key, fmt.Errorf("not a JSON %s: %w", what, err))
+			}
+			return true
+		}
+		return false
 	}
 
 	cfg.Region = getValue("OCI_REGION")
@@ -66,69 +386,306 @@ func Load(path string) (*Config, error) {
 	cfg.TenancyID = getValue("OCI_TENANCY_ID")
 	cfg.KeyFingerprint = getValue("OCI_KEY_FINGERPRINT")
 	cfg.PrivateKeyPath = getValue("OCI_PRIVATE_KEY_FILENAME")
+	cfg.CompartmentID = getValue("OCI_COMPARTMENT_ID")
 	cfg.AvailabilityDomain = getValue("OCI_AVAILABILITY_DOMAIN")
 	cfg.SubnetID = getValue("OCI_SUBNET_ID")
 	cfg.ImageID = getValue("OCI_IMAGE_ID")
+	cfg.InstanceConfigurationID = getValue("OCI_INSTANCE_CONFIGURATION_ID")
+	cfg.ImageOS = getValue("OCI_IMAGE_OS")
+	cfg.ImageOSVersion = getValue("OCI_IMAGE_OS_VERSION")
 	cfg.Shape = getValue("OCI_SHAPE")
 	cfg.SSHKey = getValue("OCI_SSH_PUBLIC_KEY")
+	if val := getValue("OCI_SSH_PUBLIC_KEY_FILE"); val != "" {
+		keys, err := readSSHKeys(val)
+		if err != nil {
+			invalid("OCI_SSH_PUBLIC_KEY_FILE", err)
+		}
+		if cfg.SSHKey != "" {
+			keys = append([]string{cfg.SSHKey}, keys...)
+		}
+		cfg.SSHKey = strings.Join(keys, "\n")
+	}
+	if val := getValue("OCI_USER_DATA_FILE"); val != "" {
+		userData, err := readUserData(val)
+		if err != nil {
+			invalid("OCI_USER_DATA_FILE", err)
+		}
+		cfg.UserData = userData
+	}
+	if !parseJSON("OCI_METADATA", &cfg.Metadata, "object of strings") {
+		cfg.Metadata, _ = get("OCI_METADATA").parsed.(map[string]string)
+	}
+	if !parseJSON("OCI_EXTENDED_METADATA", &cfg.ExtendedMetadata, "object") {
+		cfg.ExtendedMetadata, _ = get("OCI_EXTENDED_METADATA").parsed.(map[string]any)
+	}
+	if !parseJSON("OCI_REGIONS", &cfg.Regions, "array of regions") {
+		tables, _ := get("OCI_REGIONS").parsed.([]map[string]any)
+		for _, table := range tables {
+			cfg.Regions = append(cfg.Regions, regionFromTable(table))
+		}
+	}
+	if !parseJSON("OCI_BLOCK_VOLUMES", &cfg.BlockVolumes, "array of volumes") {
+		tables, _ := get("OCI_BLOCK_VOLUMES").parsed.([]map[string]any)
+		for _, table := range tables {
+			cfg.BlockVolumes = append(cfg.BlockVolumes, blockVolumeFromTable(table))
+		}
+	}
+	for i := range cfg.BlockVolumes {
+		volume := &cfg.BlockVolumes[i]
+		volume.AttachmentType = strings.ToLower(volume.AttachmentType)
+		if volume.AttachmentType == "" {
+			volume.AttachmentType = AttachmentTypeParavirtualized
+		}
+	}
 	cfg.BootVolumeID = getValue("OCI_BOOT_VOLUME_ID")
+	cfg.BootVolumeBackupID = getValue("OCI_BOOT_VOLUME_BACKUP_ID")
 	cfg.JSONLogPath = getValue("OCI_JSON_LOG_PATH")
+	cfg.CapacityReservationID = getValue("OCI_CAPACITY_RESERVATION_ID")
+	cfg.ReservedPublicIPID = getValue("OCI_RESERVED_PUBLIC_IP_ID")
+	cfg.PrivateIP = getValue("OCI_PRIVATE_IP")
+	cfg.HostnameLabel = getValue("OCI_HOSTNAME_LABEL")
+	cfg.VnicDisplayName = getValue("OCI_VNIC_DISPLAY_NAME")
+	cfg.LaunchFirmware = strings.ToUpper(getValue("OCI_LAUNCH_FIRMWARE"))
+	cfg.LaunchNetworkType = strings.ToUpper(getValue("OCI_LAUNCH_NETWORK_TYPE"))
+	cfg.LaunchBootVolumeType = strings.ToUpper(getValue("OCI_LAUNCH_BOOT_VOLUME_TYPE"))
+	cfg.PlatformType = strings.ToUpper(getValue("OCI_PLATFORM_TYPE"))
+	cfg.RecoveryAction = strings.ToUpper(getValue("OCI_RECOVERY_ACTION"))
+	if val := getValue("OCI_SCAN_STRATEGY"); val != "" {
+		cfg.ScanStrategy = strings.ToLower(val)
+	}
 
 	cfg.TelegramBotAPIKey = getValue("TELEGRAM_BOT_API_KEY")
 	cfg.TelegramUserID = getValue("TELEGRAM_USER_ID")
+	cfg.TelegramAPIURL = getValue("TELEGRAM_API_URL")
+
+	// Boolean values
+	parseBool("TELEGRAM_BOT_ENABLED", &cfg.TelegramBotEnabled)
+	parseBool("OCI_COUNT_SUBTREE", &cfg.CountSubtree)
+	parseBool("OCI_ASSIGN_PUBLIC_IP", &cfg.AssignPublicIP)
+	parseBool("OCI_ASSIGN_IPV6", &cfg.AssignIpv6)
+	parseBool("OCI_SKIP_SOURCE_DEST_CHECK", &cfg.SkipSourceDestCheck)
+	parseBool("OCI_PV_ENCRYPTION_IN_TRANSIT", &cfg.PvEncryptionInTransit)
+	parseBool("OCI_SECURE_BOOT", &cfg.SecureBoot)
+	parseBool("OCI_MEASURED_BOOT", &cfg.MeasuredBoot)
+	parseBool("OCI_TPM", &cfg.TrustedPlatformModule)
+	parseBool("OCI_DISABLE_LEGACY_IMDS", &cfg.LegacyIMDSDisabled)
+	if val := get("OCI_LIVE_MIGRATION_PREFERRED"); val.value != "" || val.parsed != nil {
+		var preferred bool
+		parseBool("OCI_LIVE_MIGRATION_PREFERRED", &preferred)
+		cfg.LiveMigrationPreferred = &preferred
+	}
+	parseBool("OCI_AGENT_MONITORING_DISABLED", &cfg.AgentMonitoringDisabled)
+	parseBool("OCI_AGENT_MANAGEMENT_DISABLED", &cfg.AgentManagementDisabled)
+	parseBool("OCI_AGENT_ALL_PLUGINS_DISABLED", &cfg.AgentAllPluginsDisabled)
+	parseBool("OCI_AUTO_SIZE", &cfg.AutoSize)
+	parseBool("OCI_ALLOW_PAID", &cfg.AllowPaid)
+	parseBool("OCI_CAPACITY_RESERVATION_MODE", &cfg.CapacityReservationMode)
+	parseBool("OCI_WATCH_MODE", &cfg.WatchMode)
 
 	// Integer values
-	if val := getValue("OCI_OCPUS"); val != "" {
-		cfg.OCPUs, _ = strconv.Atoi(val)
+	parseInt("OCI_OCPUS", &cfg.OCPUs)
+	parseInt("OCI_MEMORY_IN_GBS", &cfg.MemoryInGBs)
+	parseInt("OCI_MAX_INSTANCES", &cfg.MaxInstances)
+	parseInt("OCI_BOOT_VOLUME_SIZE_IN_GBS", &cfg.BootVolumeSizeGbs)
+	parseInt("BACKOFF_INITIAL_SECONDS", &cfg.BackoffInitialSeconds)
+	parseInt("BACKOFF_MAX_SECONDS", &cfg.BackoffMaxSeconds)
+	parseInt("OCI_WATCH_INTERVAL_SECONDS", &cfg.WatchIntervalSeconds)
+	parseInt("OCI_IMAGE_REFRESH_HOURS", &cfg.ImageRefreshHours)
+	parseInt("OCI_LIMITS_CHECK_INTERVAL_SECONDS", &cfg.LimitsCheckIntervalSeconds)
+	parseInt("OCI_RESIZE_INTERVAL_SECONDS", &cfg.ResizeIntervalSeconds)
+	parseInt("OCI_KEEPALIVE_CHECK_HOURS", &cfg.KeepAliveCheckHours)
+	parseInt("OCI_KEEPALIVE_WARN_PERCENT", &cfg.KeepAliveWarnPercent)
+
+	// List values
+	if pairs := getPairs("OCI_FREEFORM_TAGS"); len(pairs) > 0 {
+		cfg.FreeformTags = pairsMap(pairs)
 	}
-	if val := getValue("OCI_MEMORY_IN_GBS"); val != "" {
-		cfg.MemoryInGBs, _ = strconv.Atoi(val)
+	if val := get("OCI_DEFINED_TAGS"); val.value != "" {
+		parsed, err := parseDefinedTags(val.value)
+		if err != nil {
+			invalid("OCI_DEFINED_TAGS", err)
+		}
+		cfg.DefinedTags = parsed
+	} else {
+		cfg.DefinedTags, _ = val.parsed.(map[string]map[string]string)
 	}
-	if val := getValue("OCI_MAX_INSTANCES"); val != "" {
-		cfg.MaxInstances, _ = strconv.Atoi(val)
+	if pairs := getPairs("OCI_AGENT_PLUGINS"); len(pairs) > 0 {
+		parsed, err := agentPlugins(pairs)
+		if err != nil {
+			invalid("OCI_AGENT_PLUGINS", err)
+		}
+		cfg.AgentPlugins = parsed
 	}
-	if val := getValue("OCI_BOOT_VOLUME_SIZE_IN_GBS"); val != "" {
-		cfg.BootVolumeSizeGbs, _ = strconv.Atoi(val)
+	if val := getValue("OCI_COUNT_TAG"); val != "" {
+		key, value, ok := strings.Cut(val, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			invalid("OCI_COUNT_TAG", fmt.Errorf("%q is not in the form key=value", val))
+		}
+		cfg.CountTagKey, cfg.CountTagValue = strings.TrimSpace(key), strings.TrimSpace(value)
 	}
-	if val := getValue("BACKOFF_INITIAL_SECONDS"); val != "" {
-		cfg.BackoffInitialSeconds, _ = strconv.Atoi(val)
+	for _, fd := range getList("OCI_FAULT_DOMAINS") {
+		cfg.FaultDomains = append(cfg.FaultDomains, strings.ToUpper(fd))
 	}
-	if val := getValue("BACKOFF_MAX_SECONDS"); val != "" {
-		cfg.BackoffMaxSeconds, _ = strconv.Atoi(val)
+	cfg.NsgIDs = getList("OCI_NSG_IDS")
+	if items := getList("OCI_SHAPE_FALLBACKS"); len(items) > 0 {
+		parsed, err := parseShapeSizes(items)
+		if err != nil {
+			invalid("OCI_SHAPE_FALLBACKS", err)
+		}
+		cfg.ShapeFallbacks = parsed
 	}
 
-	return cfg, nil
+	return cfg, errors.Join(errs...)
 }
 
-// Validate checks if the essential configuration values are set.
-func (c *Config) Validate() error {
-	required := map[string]string{
-		"OCI_REGION":               c.Region,
-		"OCI_USER_ID":              c.UserID,
-		"OCI_TENANCY_ID":           c.TenancyID,
-		"OCI_KEY_FINGERPRINT":      c.KeyFingerprint,
-		"OCI_PRIVATE_KEY_FILENAME": c.PrivateKeyPath,
-		"OCI_SUBNET_ID":            c.SubnetID,
-		"OCI_SHAPE":                c.Shape,
-		"OCI_SSH_PUBLIC_KEY":       c.SSHKey,
+// ValidateCredentials checks that the settings needed to call the OCI API are
+// set. Subcommands that do not launch instances only need these.
+func (c *Config) ValidateCredentials() error {
+	return requireSet([]setValue{
+		{"OCI_REGION", c.Region},
+		{"OCI_USER_ID", c.UserID},
+		{"OCI_TENANCY_ID", c.TenancyID},
+		{"OCI_KEY_FINGERPRINT", c.KeyFingerprint},
+		{"OCI_PRIVATE_KEY_FILENAME", c.PrivateKeyPath},
+	})
+}
+
+// setValue is a setting's name and value, for checks that must run in a
+// fixed order.
+type setValue struct {
+	key, val string
+}
+
+// requireSet reports every setting without a value.
+func requireSet(values []setValue) error {
+	var errs []error
+	for _, v := range values {
+		if v.val == "" {
+			errs = append(errs, fmt.Errorf("mandatory configuration %s is not set", v.key))
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	// Either ImageID or BootVolumeID must be present
-	if c.ImageID == "" && c.BootVolumeID == "" {
-		return fmt.Errorf("either OCI_IMAGE_ID or OCI_BOOT_VOLUME_ID must be set")
+// Validate checks the configuration, reporting every problem found. Each
+// problem names where the settings it mentions were set.
+func (c *Config) Validate() error {
+	return c.withSources(c.validate())
+}
+
+func (c *Config) validate() error {
+	errs := []error{c.ValidateCredentials()}
+
+	required := []setValue{
+		{"OCI_SUBNET_ID", c.SubnetID},
+		{"OCI_SHAPE", c.Shape},
 	}
+	// The instance configuration supplies the image, SSH keys and everything
+	// else that is not overridden per attempt.
+	if c.InstanceConfigurationID == "" {
+		required = append(required, setValue{"OCI_SSH_PUBLIC_KEY", c.SSHKey})
+	}
+	errs = append(errs, requireSet(required))
 
-	for key, val := range required {
-		if val == "" {
-			return fmt.Errorf("mandatory configuration %s is not set", key)
+	// Exactly one boot source must be present
+	var sources []string
+	for _, source := range []setValue{
+		{"OCI_IMAGE_ID", c.ImageID},
+		{"OCI_IMAGE_OS", c.ImageOS},
+		{"OCI_BOOT_VOLUME_ID", c.BootVolumeID},
+		{"OCI_BOOT_VOLUME_BACKUP_ID", c.BootVolumeBackupID},
+		{"OCI_INSTANCE_CONFIGURATION_ID", c.InstanceConfigurationID},
+	} {
+		if source.val != "" {
+			sources = append(sources, source.key)
 		}
 	}
+	switch {
+	case len(sources) == 0:
+		errs = append(errs, fmt.Errorf("one of OCI_IMAGE_ID, OCI_IMAGE_OS, OCI_BOOT_VOLUME_ID, OCI_BOOT_VOLUME_BACKUP_ID or OCI_INSTANCE_CONFIGURATION_ID must be set"))
+	case len(sources) > 1:
+		errs = append(errs, fmt.Errorf("%s cannot be used together", strings.Join(sources, " and ")))
+	}
+
+	errs = append(errs,
+		c.validateShape(),
+		c.validateMetadata(),
+	)
 
 	if c.BootVolumeID != "" && c.BootVolumeSizeGbs > 0 {
-		return fmt.Errorf("OCI_BOOT_VOLUME_ID and OCI_BOOT_VOLUME_SIZE_IN_GBS cannot be used together")
+		errs = append(errs, fmt.Errorf("OCI_BOOT_VOLUME_ID and OCI_BOOT_VOLUME_SIZE_IN_GBS cannot be used together"))
 	}
 
-	return nil
+	if c.ScanStrategy != ScanStrategyLaunch && c.ScanStrategy != ScanStrategyReport {
+		errs = append(errs, fmt.Errorf("OCI_SCAN_STRATEGY must be %q or %q", ScanStrategyLaunch, ScanStrategyReport))
+	}
+
+	errs = append(errs,
+		c.validateTags(),
+		c.validateLaunch(),
+		c.validateAgent(),
+		c.validateBlockVolumes(),
+		c.validateRegions(),
+		c.validateVnic(),
+		c.validateIntervals(),
+	)
+
+	if c.AssignPublicIP && c.ReservedPublicIPID != "" {
+		errs = append(errs, fmt.Errorf("OCI_ASSIGN_PUBLIC_IP and OCI_RESERVED_PUBLIC_IP_ID cannot be used together"))
+	}
+	// A reserved public IP can only be attached to one instance.
+	if c.ReservedPublicIPID != "" && c.MaxInstances > 1 {
+		errs = append(errs, fmt.Errorf("OCI_RESERVED_PUBLIC_IP_ID cannot be used with OCI_MAX_INSTANCES greater than 1"))
+	}
+
+	if c.CapacityReservationMode && c.CapacityReservationID != "" {
+		errs = append(errs, fmt.Errorf("OCI_CAPACITY_RESERVATION_MODE and OCI_CAPACITY_RESERVATION_ID cannot be used together"))
+	}
+
+	if c.TelegramBotEnabled && (c.TelegramBotAPIKey == "" || c.TelegramUserID == "") {
+		errs = append(errs, fmt.Errorf("TELEGRAM_BOT_ENABLED requires TELEGRAM_BOT_API_KEY and TELEGRAM_USER_ID"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateIntervals checks counts, intervals and the watch mode settings.
+func (c *Config) validateIntervals() error {
+	var errs []error
+	for _, v := range []struct {
+		key string
+		val int
+		min int
+	}{
+		{"OCI_MAX_INSTANCES", c.MaxInstances, 1},
+		{"BACKOFF_INITIAL_SECONDS", c.BackoffInitialSeconds, 1},
+		{"BACKOFF_MAX_SECONDS", c.BackoffMaxSeconds, 1},
+		{"OCI_IMAGE_REFRESH_HOURS", c.ImageRefreshHours, 0},
+		{"OCI_LIMITS_CHECK_INTERVAL_SECONDS", c.LimitsCheckIntervalSeconds, 0},
+		{"OCI_RESIZE_INTERVAL_SECONDS", c.ResizeIntervalSeconds, 0},
+		{"OCI_KEEPALIVE_CHECK_HOURS", c.KeepAliveCheckHours, 0},
+	} {
+		if v.val < v.min {
+			errs = append(errs, fmt.Errorf("%s must be at least %d, got %d", v.key, v.min, v.val))
+		}
+	}
+	if c.BackoffMaxSeconds < c.BackoffInitialSeconds {
+		errs = append(errs, fmt.Errorf("BACKOFF_MAX_SECONDS must not be less than BACKOFF_INITIAL_SECONDS"))
+	}
+
+	if c.WatchMode && c.WatchIntervalSeconds <= 0 {
+		errs = append(errs, fmt.Errorf("OCI_WATCH_INTERVAL_SECONDS must be positive when OCI_WATCH_MODE is enabled"))
+	}
+
+	if c.KeepAliveCheckHours > 0 {
+		if !c.WatchMode {
+			errs = append(errs, fmt.Errorf("OCI_KEEPALIVE_CHECK_HOURS requires OCI_WATCH_MODE"))
+		}
+		if c.KeepAliveWarnPercent < 1 || c.KeepAliveWarnPercent > 100 {
+			errs = append(errs, fmt.Errorf("OCI_KEEPALIVE_WARN_PERCENT must be between 1 and 100"))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // defaults sets default values for the configuration.
@@ -137,28 +694,99 @@ func defaults(c *Config) {
 	c.OCPUs = 4
 	c.MemoryInGBs = 24
 	c.MaxInstances = 1
-	c.BackoffInitialSeconds = 2 // Start with a 2-second backoff
-	c.BackoffMaxSeconds = 360   // 6 minutes
+	c.ScanStrategy = ScanStrategyLaunch
+	c.BackoffInitialSeconds = 2  // Start with a 2-second backoff
+	c.BackoffMaxSeconds = 360    // 6 minutes
+	c.WatchIntervalSeconds = 600 // 10 minutes
+	c.LimitsCheckIntervalSeconds = 3600
+	c.ImageRefreshHours = 24
+	c.KeepAliveWarnPercent = 25
 }
 
-// readEnvFile parses a .env file and returns a map of key-value pairs.
-func readEnvFile(path string) (map[string]string, error) {
+// splitList splits a comma-separated value, trimming spaces and dropping empty items.
+func splitList(val string) []string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseShapeSizes parses "ocpus:memory" pairs, e.g. "4:24", "2:12", "1:6".
+func parseShapeSizes(items []string) ([]ShapeSize, error) {
+	var sizes []ShapeSize
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		ocpus, memory, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("%q is not in the form ocpus:memory", item)
+		}
+		var size ShapeSize
+		var err error
+		if size.OCPUs, err = strconv.Atoi(strings.TrimSpace(ocpus)); err != nil || size.OCPUs <= 0 {
+			return nil, fmt.Errorf("%q has an invalid OCPU count", item)
+		}
+		if size.MemoryInGBs, err = strconv.Atoi(strings.TrimSpace(memory)); err != nil || size.MemoryInGBs <= 0 {
+			return nil, fmt.Errorf("%q has an invalid memory size", item)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// sourcedValue is a setting's value and where it was set, e.g. ".env:12".
+// Values from .env files and environment variables are strings; typed config
+// file values are kept in parsed, as described by their settingKind.
+type sourcedValue struct {
+	value  string
+	parsed any
+	source string
+}
+
+// envSection is the key-value pairs of one [name] target section.
+type envSection struct {
+	name   string
+	values map[string]sourcedValue
+}
+
+// readEnvFile parses a .env file and returns the shared key-value pairs and
+// any target sections.
+func readEnvFile(path string) (map[string]sourcedValue, []envSection, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer file.Close()
 
-	envMap := make(map[string]string)
+	shared := make(map[string]sourcedValue)
+	envMap := shared
+	var sections []envSection
+	seen := make(map[string]bool)
 	scanner := bufio.NewScanner(file)
+	lineNumber := 0
 
 	for scanner.Scan() {
+		lineNumber++
 		line := strings.TrimSpace(scanner.Text())
 
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 
+		// A [name] header starts a target section.
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" || seen[name] {
+				return nil, nil, fmt.Errorf("%s:%d: invalid or duplicate target section %q", path, lineNumber, line)
+			}
+			seen[name] = true
+			sections = append(sections, envSection{name: name, values: make(map[string]sourcedValue)})
+			envMap = sections[len(sections)-1].values
+			continue
+		}
+
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
 			continue
@@ -172,12 +800,12 @@ func readEnvFile(path string) (map[string]string, error) {
 			value = value[1 : len(value)-1]
 		}
 
-		envMap[key] = value
+		envMap[key] = sourcedValue{value: value, source: fmt.Sprintf("%s:%d", path, lineNumber)}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return envMap, nil
+	return shared, sections, nil
 }
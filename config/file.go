@@ -0,0 +1,309 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// readConfigFile parses a TOML config file and returns the shared settings
+// and any [targets.<name>] tables, keyed by environment variable name like
+// readEnvFile. Values are checked against the settings schema and kept as Go
+// values, and errors are reported as a *FileError with the file and line.
+func readConfigFile(path string) (map[string]sourcedValue, []envSection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := parseTOML(path, string(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var targets *tomlNode
+	if node, ok := doc["targets"]; ok {
+		targets = node
+		delete(doc, "targets")
+	}
+
+	shared, err := convertSettings(path, doc)
+	if targets == nil {
+		return shared, nil, err
+	}
+	errs := []error{err}
+
+	table, ok := targets.value.(tomlTable)
+	if !ok {
+		errs = append(errs, &FileError{Path: path, Line: targets.line, Msg: "targets must be a table of named targets"})
+		return nil, nil, errors.Join(errs...)
+	}
+	var sections []envSection
+	for _, name := range keysByLine(table) {
+		node := table[name]
+		values, ok := node.value.(tomlTable)
+		if !ok {
+			errs = append(errs, &FileError{Path: path, Line: node.line, Msg: fmt.Sprintf("target %q must be a table", name)})
+			continue
+		}
+		converted, err := convertSettings(path, values)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sections = append(sections, envSection{name: name, values: converted})
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+	return shared, sections, nil
+}
+
+// convertSettings converts a table of settings to their Go values, keyed by
+// environment variable name, reporting every invalid setting.
+func convertSettings(path string, table tomlTable) (map[string]sourcedValue, error) {
+	values := make(map[string]sourcedValue, len(table))
+	var errs []error
+	for _, key := range keysByLine(table) {
+		node := table[key]
+		s, ok := settingsByFileKey[key]
+		if !ok {
+			msg := fmt.Sprintf("unknown setting %q", key)
+			if suggestion := suggestKey(key, fileKeys()); suggestion != "" {
+				msg += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+			errs = append(errs, &FileError{Path: path, Line: node.line, Msg: msg})
+			continue
+		}
+		val, err := s.convert(node)
+		if err != nil {
+			// Errors in nested values point at their own line.
+			line := node.line
+			var lineErr *lineError
+			if errors.As(err, &lineErr) {
+				line = lineErr.line
+			}
+			errs = append(errs, &FileError{Path: path, Line: line, Msg: fmt.Sprintf("%s: %v", key, err)})
+			continue
+		}
+		source := fmt.Sprintf("%s:%d", path, node.line)
+		if str, ok := val.(string); ok {
+			values[s.env] = sourcedValue{value: str, source: source}
+		} else {
+			values[s.env] = sourcedValue{parsed: val, source: source}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// lineError is an error in a nested value, on a later line than its key.
+type lineError struct {
+	line int
+	err  error
+}
+
+func (e *lineError) Error() string { return e.err.Error() }
+
+func (e *lineError) Unwrap() error { return e.err }
+
+// convert checks a config file value against the setting's kind and returns
+// its Go value: a string, int, bool, []string, []keyValue, a map of strings,
+// a map of maps of strings, a map[string]any or a []map[string]any.
+func (s setting) convert(node *tomlNode) (any, error) {
+	switch s.kind {
+	case kindList:
+		items, err := stringList(node)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if s.enum != nil && !slices.Contains(s.enum, strings.ToUpper(item)) {
+				return nil, fmt.Errorf("%q is not one of %s", item, strings.Join(s.enum, ", "))
+			}
+		}
+		return items, nil
+
+	case kindPairs:
+		return stringPairs(node)
+
+	case kindNamespacedPairs:
+		table, ok := node.value.(tomlTable)
+		if !ok {
+			return nil, fmt.Errorf("must be a table of namespaces")
+		}
+		namespaces := make(map[string]map[string]string, len(table))
+		for _, namespace := range sortedKeys(table) {
+			pairs, err := stringPairs(table[namespace])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", namespace, err)
+			}
+			namespaces[namespace] = pairsMap(pairs)
+		}
+		return namespaces, nil
+
+	case kindStringObject:
+		pairs, err := stringPairs(node)
+		if err != nil {
+			return nil, err
+		}
+		return pairsMap(pairs), nil
+
+	case kindObject:
+		if _, ok := node.value.(tomlTable); !ok {
+			return nil, fmt.Errorf("must be a table")
+		}
+		return plainValue(node), nil
+
+	case kindObjectList:
+		items, ok := node.value.([]*tomlNode)
+		if !ok {
+			return nil, fmt.Errorf("must be an array of tables")
+		}
+		tables := make([]map[string]any, len(items))
+		for i, item := range items {
+			table, err := fieldValues(item, s.fields)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i+1, err)
+			}
+			tables[i] = table
+		}
+		return tables, nil
+	}
+
+	val, err := scalarValue(node, s.kind)
+	if err != nil {
+		return nil, err
+	}
+	if str, ok := val.(string); ok && s.enum != nil && !slices.Contains(s.enum, strings.ToUpper(str)) && !slices.Contains(s.enum, strings.ToLower(str)) {
+		return nil, fmt.Errorf("%q is not one of %s", str, strings.Join(s.enum, ", "))
+	}
+	return val, nil
+}
+
+// scalarValue checks a string, integer or boolean value and returns it as a
+// string, int or bool.
+func scalarValue(node *tomlNode, kind settingKind) (any, error) {
+	switch kind {
+	case kindInteger:
+		if n, ok := node.value.(int64); ok {
+			return int(n), nil
+		}
+		return nil, fmt.Errorf("must be an integer")
+	case kindBoolean:
+		if b, ok := node.value.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("must be true or false")
+	}
+	if s, ok := node.value.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("must be a string")
+}
+
+// stringList checks an array of strings.
+func stringList(node *tomlNode) ([]string, error) {
+	items, ok := node.value.([]*tomlNode)
+	if !ok {
+		return nil, fmt.Errorf("must be an array of strings")
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.value.(string)
+		if !ok {
+			return nil, &lineError{item.line, fmt.Errorf("must be an array of strings")}
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// stringPairs checks a table of strings and returns its entries in the
+// order they appear in the file.
+func stringPairs(node *tomlNode) ([]keyValue, error) {
+	table, ok := node.value.(tomlTable)
+	if !ok {
+		return nil, fmt.Errorf("must be a table of strings")
+	}
+	pairs := make([]keyValue, 0, len(table))
+	for _, key := range keysByLine(table) {
+		value, ok := table[key].value.(string)
+		if !ok {
+			return nil, &lineError{table[key].line, fmt.Errorf("%s must be a string", key)}
+		}
+		pairs = append(pairs, keyValue{key: key, value: value})
+	}
+	return pairs, nil
+}
+
+// fieldValues checks a table against the fields of an object list and
+// returns its values by field name.
+func fieldValues(node *tomlNode, fields []field) (map[string]any, error) {
+	table, ok := node.value.(tomlTable)
+	if !ok {
+		return nil, fmt.Errorf("must be a table")
+	}
+	values := make(map[string]any, len(table))
+	for _, key := range keysByLine(table) {
+		value := table[key]
+		i := slices.IndexFunc(fields, func(f field) bool { return f.name == key })
+		if i < 0 {
+			return nil, &lineError{value.line, fmt.Errorf("unknown key %q", key)}
+		}
+		val, err := scalarValue(value, fields[i].kind)
+		if err != nil {
+			return nil, &lineError{value.line, fmt.Errorf("%s %w", key, err)}
+		}
+		if str, ok := val.(string); ok && fields[i].enum != nil && !slices.Contains(fields[i].enum, strings.ToLower(str)) {
+			return nil, &lineError{value.line, fmt.Errorf("%s: %q is not one of %s", key, str, strings.Join(fields[i].enum, ", "))}
+		}
+		values[key] = val
+	}
+	for _, f := range fields {
+		if _, ok := table[f.name]; f.required && !ok {
+			return nil, &lineError{node.line, fmt.Errorf("%s is required", f.name)}
+		}
+	}
+	return values, nil
+}
+
+// plainValue converts a parsed value to plain Go values for JSON encoding.
+func plainValue(node *tomlNode) any {
+	switch value := node.value.(type) {
+	case tomlTable:
+		plain := make(map[string]any, len(value))
+		for key, item := range value {
+			plain[key] = plainValue(item)
+		}
+		return plain
+	case []*tomlNode:
+		plain := make([]any, len(value))
+		for i, item := range value {
+			plain[i] = plainValue(item)
+		}
+		return plain
+	default:
+		return value
+	}
+}
+
+// keysByLine returns the keys of a table in the order they appear in the file.
+func keysByLine(table tomlTable) []string {
+	keys := sortedKeys(table)
+	sort.SliceStable(keys, func(i, j int) bool { return table[keys[i]].line < table[keys[j]].line })
+	return keys
+}
+
+func sortedKeys(table tomlTable) []string {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to name in a temporary directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadTargetsConfigFile(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+region = "us-ashburn-1"
+ocpus = 2
+memory_in_gbs = 12
+assign_ipv6 = true
+shape_fallbacks = ["1:6"]
+fault_domains = ["fault-domain-1", "FAULT-DOMAIN-2"]
+nsg_ids = ["ocid1.nsg.a,b"]
+metadata = { note = "a, b" }
+extended_metadata = { nested = { n = 1 } }
+
+[agent_plugins]
+"Vulnerability Scanning" = "enabled"
+Bastion = "DISABLED"
+
+[freeform_tags]
+Owner = "me, myself"
+
+[defined_tags.Ops]
+CostCenter = "42"
+
+[[regions]]
+region = "eu-frankfurt-1"
+subnetId = "ocid1.subnet.fra"
+imageOs = "Canonical Ubuntu"
+
+[[block_volumes]]
+sizeInGBs = 100
+vpusPerGB = 20
+readOnly = true
+`)
+	targets, err := LoadTargets(path, filepath.Join(t.TempDir(), "missing.env"))
+	if err != nil {
+		t.Fatalf("LoadTargets: %v", err)
+	}
+	cfg := targets[0]
+
+	vpus := 20
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"Region", cfg.Region, "us-ashburn-1"},
+		{"OCPUs", cfg.OCPUs, 2},
+		{"MemoryInGBs", cfg.MemoryInGBs, 12},
+		{"AssignIpv6", cfg.AssignIpv6, true},
+		{"ShapeFallbacks", cfg.ShapeFallbacks, []ShapeSize{{OCPUs: 1, MemoryInGBs: 6}}},
+		{"FaultDomains", cfg.FaultDomains, []string{"FAULT-DOMAIN-1", "FAULT-DOMAIN-2"}},
+		// Values are taken as is, so commas need no escaping.
+		{"NsgIDs", cfg.NsgIDs, []string{"ocid1.nsg.a,b"}},
+		{"Metadata", cfg.Metadata, map[string]string{"note": "a, b"}},
+		{"ExtendedMetadata", cfg.ExtendedMetadata, map[string]any{"nested": map[string]any{"n": int64(1)}}},
+		{"AgentPlugins", cfg.AgentPlugins, []AgentPlugin{{Name: "Vulnerability Scanning", DesiredState: "ENABLED"}, {Name: "Bastion", DesiredState: "DISABLED"}}},
+		{"FreeformTags", cfg.FreeformTags, map[string]string{"Owner": "me, myself"}},
+		{"DefinedTags", cfg.DefinedTags, map[string]map[string]string{"Ops": {"CostCenter": "42"}}},
+		{"Regions", cfg.Regions, []RegionConfig{{Region: "eu-frankfurt-1", SubnetID: "ocid1.subnet.fra", ImageOS: "Canonical Ubuntu"}}},
+		{"BlockVolumes", cfg.BlockVolumes, []BlockVolume{{SizeInGBs: 100, VpusPerGB: &vpus, ReadOnly: true, AttachmentType: AttachmentTypeParavirtualized}}},
+	}
+	for _, c := range checks {
+		if !reflect.DeepEqual(c.got, c.want) {
+			t.Errorf("%s = %#v, want %#v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestReadConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		lines []int
+		msg   string
+	}{
+		{"wrong type", "region = \"x\"\nocpus = \"four\"", []int{2}, "ocpus: must be an integer"},
+		{"unknown setting", "\n\nocpu = 4", []int{3}, `did you mean "ocpus"?`},
+		{"enum", "scan_strategy = \"fast\"", []int{1}, `"fast" is not one of`},
+		{"nested value", "nsg_ids = [\n  \"a\",\n  1,\n]", []int{3}, "nsg_ids: must be an array of strings"},
+		{"object list field", "[[regions]]\nregion = \"x\"\nsubnet = \"y\"", []int{3}, `unknown key "subnet"`},
+		{"object list required", "[[regions]]\nregion = \"x\"", []int{1}, "subnetId is required"},
+		{"every error", "ocpus = true\nmemory_in_gbs = \"x\"", []int{1, 2}, ""},
+		{"target", "[targets.a]\nocpus = \"x\"", []int{2}, "ocpus: must be an integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "config.toml", tt.src)
+			_, _, err := readConfigFile(path)
+			if err == nil {
+				t.Fatal("got no error")
+			}
+			var lines []int
+			for _, e := range leafErrors(err) {
+				var fileErr *FileError
+				if !errors.As(e, &fileErr) {
+					t.Fatalf("%v is not a *FileError", e)
+				}
+				lines = append(lines, fileErr.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.lines) {
+				t.Errorf("errors on lines %v, want %v: %v", lines, tt.lines, err)
+			}
+			if !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("got %q, want it to contain %q", err, tt.msg)
+			}
+		})
+	}
+}
+
+// leafErrors flattens joined errors.
+func leafErrors(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, leafErrors(e)...)
+	}
+	return errs
+}
+
+func TestLoadTargetsPrecedence(t *testing.T) {
+	configPath := writeFile(t, "config.toml", `
+ocpus = 1
+memory_in_gbs = 6
+shape = "VM.Standard.E2.1.Micro"
+
+[targets.a]
+ocpus = 2
+`)
+	envPath := writeFile(t, ".env", "OCI_OCPUS=3\nOCI_MEMORY_IN_GBS=18\n[b]\nOCI_OCPUS=4\n")
+	t.Setenv("OCI_MEMORY_IN_GBS", "12")
+	t.Setenv("OCI_SHAPE", "VM.Standard.A1.Flex")
+
+	targets, err := LoadTargets(configPath, envPath)
+	if err != nil {
+		t.Fatalf("LoadTargets: %v", err)
+	}
+	got := make(map[string]*Config)
+	for _, cfg := range targets {
+		got[cfg.Name] = cfg
+	}
+
+	tests := []struct {
+		target, field string
+		got, want     any
+	}{
+		// A target's config file value beats a shared .env value.
+		{"a", "OCPUs", got["a"].OCPUs, 2},
+		// A target's .env value beats everything.
+		{"b", "OCPUs", got["b"].OCPUs, 4},
+		// Shared .env beats the environment, which beats the shared config file.
+		{"a", "MemoryInGBs", got["a"].MemoryInGBs, 18},
+		{"a", "Shape", got["a"].Shape, "VM.Standard.A1.Flex"},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("target %s: %s = %v, want %v", tt.target, tt.field, tt.got, tt.want)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	var schema struct {
+		Properties map[string]map[string]any `json:"properties"`
+		Defs       struct {
+			Target struct {
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"target"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	for _, s := range settings {
+		property, ok := schema.Properties[s.fileKey()]
+		if !ok {
+			t.Errorf("schema lacks %s", s.fileKey())
+			continue
+		}
+		if _, ok := schema.Defs.Target.Properties[s.fileKey()]; !ok {
+			t.Errorf("target schema lacks %s", s.fileKey())
+		}
+		want := map[settingKind]string{
+			kindString: "string", kindInteger: "integer", kindBoolean: "boolean", kindList: "array",
+			kindPairs: "object", kindNamespacedPairs: "object", kindStringObject: "object",
+			kindObject: "object", kindObjectList: "array",
+		}[s.kind]
+		if property["type"] != want {
+			t.Errorf("%s has type %v, want %s", s.fileKey(), property["type"], want)
+		}
+	}
+	if _, ok := schema.Properties["targets"]; !ok {
+		t.Error("schema lacks targets")
+	}
+
+	// The checked-in schema must be regenerated when settings change.
+	checkedIn, err := os.ReadFile("../config.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(checkedIn)) != strings.TrimSpace(string(data)) {
+		t.Error("config.schema.json is out of date; regenerate it with `oahc-go schema`")
+	}
+}
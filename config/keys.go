@@ -0,0 +1,109 @@
+package config
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// maxTypoDistance is the largest edit distance at which an unknown key is
+// taken for a misspelling of a known one.
+const maxTypoDistance = 2
+
+// envKeys returns the environment variable names of all settings.
+func envKeys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.env
+	}
+	return keys
+}
+
+// fileKeys returns the config file names of all settings.
+func fileKeys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.fileKey()
+	}
+	return keys
+}
+
+// suggestKey returns the known key closest to an unknown one, or "" if none
+// is close enough to be a likely typo. Case is ignored.
+func suggestKey(key string, known []string) string {
+	best, bestDistance := "", maxTypoDistance+1
+	for _, candidate := range known {
+		if d := editDistance(strings.ToUpper(key), strings.ToUpper(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// warnUnknownKeys logs a warning for .env keys and environment variables
+// that are not settings but look like misspellings of one. Other unknown
+// keys are left alone, as the environment holds many unrelated variables.
+func warnUnknownKeys(shared map[string]sourcedValue, sections []envSection) {
+	known := envKeys()
+	isKnown := make(map[string]bool, len(known))
+	for _, key := range known {
+		isKnown[key] = true
+	}
+
+	warn := func(key, source string) {
+		if isKnown[key] {
+			return
+		}
+		if suggestion := suggestKey(key, known); suggestion != "" {
+			log.Printf("Warning: unknown setting %s (%s), did you mean %s?", key, source, suggestion)
+		}
+	}
+
+	for _, values := range append([]map[string]sourcedValue{shared}, sectionValues(sections)...) {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			warn(key, values[key].source)
+		}
+	}
+
+	environ := os.Environ()
+	sort.Strings(environ)
+	for _, entry := range environ {
+		key, _, _ := strings.Cut(entry, "=")
+		warn(key, "environment")
+	}
+}
+
+func sectionValues(sections []envSection) []map[string]sourcedValue {
+	values := make([]map[string]sourcedValue, len(sections))
+	for i, section := range sections {
+		values[i] = section.values
+	}
+	return values
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
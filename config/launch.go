@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Allowed values for the launch settings, as defined by the OCI Compute API.
+var (
+	faultDomains          = []string{"FAULT-DOMAIN-1", "FAULT-DOMAIN-2", "FAULT-DOMAIN-3"}
+	launchFirmwares       = []string{"BIOS", "UEFI_64"}
+	launchNetworkTypes    = []string{"E1000", "VFIO", "PARAVIRTUALIZED"}
+	launchBootVolumeTypes = []string{"ISCSI", "SCSI", "IDE", "VFIO", "PARAVIRTUALIZED"}
+	platformTypes         = []string{"AMD_VM", "INTEL_VM", "AMD_MILAN_BM", "AMD_MILAN_BM_GPU", "AMD_ROME_BM", "AMD_ROME_BM_GPU", "INTEL_ICELAKE_BM", "INTEL_SKYLAKE_BM", "GENERIC_BM"}
+	recoveryActions       = []string{"RESTORE_INSTANCE", "STOP_INSTANCE"}
+)
+
+// HasPlatformConfig reports whether any Shielded Instance option is set.
+func (c *Config) HasPlatformConfig() bool {
+	return c.PlatformType != "" || c.SecureBoot || c.MeasuredBoot || c.TrustedPlatformModule
+}
+
+// validateLaunch checks fault domains, launch options, platform config and
+// availability config.
+func (c *Config) validateLaunch() error {
+	var errs []error
+	for _, fd := range c.FaultDomains {
+		if !slices.Contains(faultDomains, fd) {
+			errs = append(errs, fmt.Errorf("OCI_FAULT_DOMAINS: %q is not one of %s", fd, strings.Join(faultDomains, ", ")))
+		}
+	}
+
+	checks := []struct {
+		key, val string
+		allowed  []string
+	}{
+		{"OCI_LAUNCH_FIRMWARE", c.LaunchFirmware, launchFirmwares},
+		{"OCI_LAUNCH_NETWORK_TYPE", c.LaunchNetworkType, launchNetworkTypes},
+		{"OCI_LAUNCH_BOOT_VOLUME_TYPE", c.LaunchBootVolumeType, launchBootVolumeTypes},
+		{"OCI_PLATFORM_TYPE", c.PlatformType, platformTypes},
+		{"OCI_RECOVERY_ACTION", c.RecoveryAction, recoveryActions},
+	}
+	for _, check := range checks {
+		if check.val != "" && !slices.Contains(check.allowed, check.val) {
+			errs = append(errs, fmt.Errorf("%s must be one of %s", check.key, strings.Join(check.allowed, ", ")))
+		}
+	}
+
+	if c.HasPlatformConfig() {
+		if c.PlatformType == "" {
+			errs = append(errs, fmt.Errorf("OCI_PLATFORM_TYPE is required when OCI_SECURE_BOOT, OCI_MEASURED_BOOT or OCI_TPM is set"))
+		}
+		if strings.Contains(c.Shape, ".A1.") {
+			errs = append(errs, fmt.Errorf("Shielded Instance options are not supported on %s", c.Shape))
+		}
+		if c.MeasuredBoot && !c.TrustedPlatformModule {
+			errs = append(errs, fmt.Errorf("OCI_MEASURED_BOOT requires OCI_TPM"))
+		}
+		if c.SecureBoot && c.LaunchFirmware == "BIOS" {
+			errs = append(errs, fmt.Errorf("OCI_SECURE_BOOT requires UEFI_64 firmware"))
+		}
+	}
+	return errors.Join(errs...)
+}
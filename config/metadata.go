@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// maxMetadataBytes is OCI's limit on the combined size of an instance's
+// metadata and extendedMetadata, including user_data.
+const maxMetadataBytes = 32000
+
+// Metadata keys managed by dedicated settings.
+const (
+	metadataSSHKeys  = "ssh_authorized_keys"
+	metadataUserData = "user_data"
+)
+
+// InstanceMetadata returns the metadata to set on new instances: SSH keys,
+// user_data and any extra OCI_METADATA entries.
+func (c *Config) InstanceMetadata() map[string]string {
+	metadata := make(map[string]string, len(c.Metadata)+2)
+	for key, val := range c.Metadata {
+		metadata[key] = val
+	}
+	metadata[metadataSSHKeys] = c.SSHKey
+	if c.UserData != "" {
+		metadata[metadataUserData] = c.UserData
+	}
+	return metadata
+}
+
+// validateMetadata checks the metadata against OCI's size limit.
+func (c *Config) validateMetadata() error {
+	var errs []error
+	for _, key := range []string{metadataSSHKeys, metadataUserData} {
+		if _, ok := c.Metadata[key]; ok {
+			errs = append(errs, fmt.Errorf("OCI_METADATA must not set %q, use its dedicated setting instead", key))
+		}
+	}
+
+	metadata, err := json.Marshal(c.InstanceMetadata())
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("failed to encode instance metadata: %w", err))...)
+	}
+	extended, err := json.Marshal(c.ExtendedMetadata)
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("failed to encode OCI_EXTENDED_METADATA: %w", err))...)
+	}
+	if size := len(metadata) + len(extended); size > maxMetadataBytes {
+		errs = append(errs, fmt.Errorf("instance metadata is %d bytes (user_data is %d bytes base64-encoded), exceeding OCI's limit of %d bytes",
+			size, len(c.UserData), maxMetadataBytes))
+	}
+	return errors.Join(errs...)
+}
+
+// readUserData reads a cloud-init file and returns it base64-encoded, as OCI expects.
+func readUserData(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// readSSHKeys reads public keys from a file, one per line, skipping blank lines and comments.
+func readSSHKeys(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
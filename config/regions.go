@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RegionConfig is an additional region to scan, with the settings that are
+// regional in OCI. Everything else is shared with the primary region.
+type RegionConfig struct {
+	Region             string `json:"region"`
+	SubnetID           string `json:"subnetId"`
+	ImageID            string `json:"imageId,omitempty"`
+	ImageOS            string `json:"imageOs,omitempty"`
+	ImageOSVersion     string `json:"imageOsVersion,omitempty"`
+	AvailabilityDomain string `json:"availabilityDomain,omitempty"`
+}
+
+// regionFromTable converts an OCI_REGIONS table from a config file, whose
+// values have been checked against regionFields.
+func regionFromTable(table map[string]any) RegionConfig {
+	str := func(key string) string {
+		s, _ := table[key].(string)
+		return s
+	}
+	return RegionConfig{
+		Region:             str("region"),
+		SubnetID:           str("subnetId"),
+		ImageID:            str("imageId"),
+		ImageOS:            str("imageOs"),
+		ImageOSVersion:     str("imageOsVersion"),
+		AvailabilityDomain: str("availabilityDomain"),
+	}
+}
+
+// ForRegion returns a copy of the configuration for an additional region.
+// Images are taken from the region if it sets one, otherwise OCI_IMAGE_OS is
+// resolved there too.
+func (c *Config) ForRegion(rc RegionConfig) *Config {
+	regional := *c
+	regional.Regions = nil
+	regional.Region = rc.Region
+	regional.SubnetID = rc.SubnetID
+	regional.AvailabilityDomain = rc.AvailabilityDomain
+	if rc.ImageID != "" || rc.ImageOS != "" {
+		regional.ImageID = rc.ImageID
+		regional.ImageOS = rc.ImageOS
+		regional.ImageOSVersion = rc.ImageOSVersion
+	}
+	return &regional
+}
+
+// validateRegions checks OCI_REGIONS. OCIDs of regional resources cannot be
+// shared between regions, so settings that name one are rejected.
+func (c *Config) validateRegions() error {
+	if len(c.Regions) == 0 {
+		return nil
+	}
+
+	regional := []struct {
+		key string
+		set bool
+	}{
+		{"OCI_BOOT_VOLUME_ID", c.BootVolumeID != ""},
+		{"OCI_BOOT_VOLUME_BACKUP_ID", c.BootVolumeBackupID != ""},
+		{"OCI_INSTANCE_CONFIGURATION_ID", c.InstanceConfigurationID != ""},
+		{"OCI_CAPACITY_RESERVATION_ID", c.CapacityReservationID != ""},
+		{"OCI_CAPACITY_RESERVATION_MODE", c.CapacityReservationMode},
+		{"OCI_RESERVED_PUBLIC_IP_ID", c.ReservedPublicIPID != ""},
+		{"OCI_NSG_IDS", len(c.NsgIDs) > 0},
+	}
+	var errs []error
+	for _, setting := range regional {
+		if setting.set {
+			errs = append(errs, fmt.Errorf("%s cannot be used with OCI_REGIONS", setting.key))
+		}
+	}
+	if slices.ContainsFunc(c.BlockVolumes, func(volume BlockVolume) bool { return volume.VolumeID != "" }) {
+		errs = append(errs, fmt.Errorf("OCI_BLOCK_VOLUMES with volumeId cannot be used with OCI_REGIONS"))
+	}
+
+	seen := map[string]bool{strings.ToLower(c.Region): true}
+	for i, rc := range c.Regions {
+		name := fmt.Sprintf("OCI_REGIONS[%d]", i)
+		if rc.Region == "" || rc.SubnetID == "" {
+			errs = append(errs, fmt.Errorf("%s: region and subnetId are required", name))
+		}
+		if rc.Region != "" && seen[strings.ToLower(rc.Region)] {
+			errs = append(errs, fmt.Errorf("%s: region %s is listed more than once (OCI_REGION is included automatically)", name, rc.Region))
+		}
+		seen[strings.ToLower(rc.Region)] = true
+		if rc.ImageID != "" && rc.ImageOS != "" {
+			errs = append(errs, fmt.Errorf("%s: imageId and imageOs cannot be used together", name))
+		}
+		if rc.ImageID == "" && rc.ImageOS == "" && c.ImageOS == "" {
+			errs = append(errs, fmt.Errorf("%s: image OCIDs are regional, set imageId or imageOs (or use OCI_IMAGE_OS)", name))
+		}
+	}
+	return errors.Join(errs...)
+}
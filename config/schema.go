@@ -0,0 +1,238 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// settingKind is how a setting is typed in config files and which Go value
+// it is converted to.
+type settingKind int
+
+const (
+	kindString          settingKind = iota
+	kindInteger                     // TOML integer, an int
+	kindBoolean                     // TOML boolean, a bool
+	kindList                        // array of strings, a []string
+	kindPairs                       // table of strings, a []keyValue in file order
+	kindNamespacedPairs             // table of tables of strings, a map[string]map[string]string
+	kindStringObject                // table of strings, a map[string]string
+	kindObject                      // table of any values, a map[string]any
+	kindObjectList                  // array of tables, a []map[string]any by field name
+)
+
+// setting is a configuration key. In config files its name is the
+// environment variable lowercased, without the OCI_ prefix.
+type setting struct {
+	env         string
+	kind        settingKind
+	description string
+	enum        []string
+	fields      []field // Keys of each table of a kindObjectList
+}
+
+// field is a key of the tables in a kindObjectList setting.
+type field struct {
+	name     string
+	kind     settingKind // kindString, kindInteger or kindBoolean
+	required bool
+	enum     []string
+}
+
+// fileKey returns the config file name of the setting.
+func (s setting) fileKey() string {
+	return strings.ToLower(strings.TrimPrefix(s.env, "OCI_"))
+}
+
+var regionFields = []field{
+	{name: "region", kind: kindString, required: true},
+	{name: "subnetId", kind: kindString, required: true},
+	{name: "imageId", kind: kindString},
+	{name: "imageOs", kind: kindString},
+	{name: "imageOsVersion", kind: kindString},
+	{name: "availabilityDomain", kind: kindString},
+}
+
+var blockVolumeFields = []field{
+	{name: "volumeId", kind: kindString},
+	{name: "displayName", kind: kindString},
+	{name: "sizeInGBs", kind: kindInteger},
+	{name: "vpusPerGB", kind: kindInteger},
+	{name: "attachmentType", kind: kindString, enum: []string{AttachmentTypeParavirtualized, AttachmentTypeISCSI}},
+	{name: "readOnly", kind: kindBoolean},
+}
+
+// settings lists every configuration key. .env.example documents them in detail.
+var settings = []setting{
+	{env: "OCI_REGION", description: "Home region, e.g. us-ashburn-1."},
+	{env: "OCI_REGIONS", kind: kindObjectList, description: "Additional regions to scan, each with its own subnet and image.", fields: regionFields},
+	{env: "OCI_USER_ID", description: "User OCID."},
+	{env: "OCI_TENANCY_ID", description: "Tenancy OCID."},
+	{env: "OCI_KEY_FINGERPRINT", description: "Fingerprint of the API signing key."},
+	{env: "OCI_PRIVATE_KEY_FILENAME", description: "Path to the API signing private key."},
+	{env: "OCI_COMPARTMENT_ID", description: "Compartment OCID or name path. Defaults to the tenancy root."},
+	{env: "OCI_COUNT_SUBTREE", kind: kindBoolean, description: "Count instances in the whole compartment subtree."},
+	{env: "OCI_AVAILABILITY_DOMAIN", description: "Availability domain to try. All are tried if empty."},
+	{env: "OCI_SUBNET_ID", description: "Subnet OCID."},
+	{env: "OCI_IMAGE_ID", description: "Image OCID."},
+	{env: "OCI_IMAGE_OS", description: "Operating system to resolve the newest image for, e.g. Canonical Ubuntu."},
+	{env: "OCI_IMAGE_OS_VERSION", description: "Operating system version for image_os, e.g. 24.04."},
+	{env: "OCI_IMAGE_REFRESH_HOURS", kind: kindInteger, description: "How often to re-resolve the image, in hours."},
+	{env: "OCI_INSTANCE_CONFIGURATION_ID", description: "Instance configuration to launch from."},
+	{env: "OCI_BOOT_VOLUME_ID", description: "Existing boot volume to launch from."},
+	{env: "OCI_BOOT_VOLUME_BACKUP_ID", description: "Boot volume backup to restore and launch from."},
+	{env: "OCI_BOOT_VOLUME_SIZE_IN_GBS", kind: kindInteger, description: "Boot volume size in GB."},
+	{env: "OCI_SHAPE", description: "Instance shape, e.g. VM.Standard.A1.Flex."},
+	{env: "OCI_OCPUS", kind: kindInteger, description: "OCPUs per instance."},
+	{env: "OCI_MEMORY_IN_GBS", kind: kindInteger, description: "Memory per instance in GB."},
+	{env: "OCI_SHAPE_FALLBACKS", kind: kindList, description: "Smaller ocpus:memory sizes to try on out of capacity, e.g. [\"2:12\", \"1:6\"]."},
+	{env: "OCI_RESIZE_INTERVAL_SECONDS", kind: kindInteger, description: "How often to try resizing fallback-sized instances back up."},
+	{env: "OCI_AUTO_SIZE", kind: kindBoolean, description: "Size launches to the free-tier budget left by existing A1 instances."},
+	{env: "OCI_ALLOW_PAID", kind: kindBoolean, description: "Allow launches beyond the Always Free limits."},
+	{env: "OCI_MAX_INSTANCES", kind: kindInteger, description: "Stop when this many instances of the shape exist."},
+	{env: "OCI_SSH_PUBLIC_KEY", description: "Public SSH keys, one per line."},
+	{env: "OCI_SSH_PUBLIC_KEY_FILE", description: "File with additional public SSH keys, one per line."},
+	{env: "OCI_USER_DATA_FILE", description: "cloud-init file run on first boot."},
+	{env: "OCI_METADATA", kind: kindStringObject, description: "Extra instance metadata."},
+	{env: "OCI_EXTENDED_METADATA", kind: kindObject, description: "Extra extended instance metadata."},
+	{env: "OCI_FREEFORM_TAGS", kind: kindPairs, description: "Freeform tags for new instances. Values may use templates such as {{.LaunchTime}}."},
+	{env: "OCI_DEFINED_TAGS", kind: kindNamespacedPairs, description: "Defined tags for new instances, by namespace."},
+	{env: "OCI_COUNT_TAG", description: "Only count instances with this key=value freeform tag."},
+	{env: "OCI_ASSIGN_PUBLIC_IP", kind: kindBoolean, description: "Assign an ephemeral public IP."},
+	{env: "OCI_RESERVED_PUBLIC_IP_ID", description: "Reserved public IP to attach once the instance is running."},
+	{env: "OCI_BLOCK_VOLUMES", kind: kindObjectList, description: "Block volumes to create or attach once the instance is running.", fields: blockVolumeFields},
+	{env: "OCI_NSG_IDS", kind: kindList, description: "Network security groups for the primary VNIC."},
+	{env: "OCI_PRIVATE_IP", description: "Fixed private IP for the primary VNIC."},
+	{env: "OCI_HOSTNAME_LABEL", description: "Hostname label for the primary VNIC."},
+	{env: "OCI_ASSIGN_IPV6", kind: kindBoolean, description: "Assign an IPv6 address."},
+	{env: "OCI_SKIP_SOURCE_DEST_CHECK", kind: kindBoolean, description: "Skip the source/destination check on the primary VNIC."},
+	{env: "OCI_VNIC_DISPLAY_NAME", description: "Display name of the primary VNIC."},
+	{env: "OCI_FAULT_DOMAINS", kind: kindList, description: "Fault domains to rotate through within each availability domain.", enum: faultDomains},
+	{env: "OCI_LAUNCH_FIRMWARE", description: "Firmware launch option.", enum: launchFirmwares},
+	{env: "OCI_LAUNCH_NETWORK_TYPE", description: "Network launch option.", enum: launchNetworkTypes},
+	{env: "OCI_LAUNCH_BOOT_VOLUME_TYPE", description: "Boot volume launch option.", enum: launchBootVolumeTypes},
+	{env: "OCI_PV_ENCRYPTION_IN_TRANSIT", kind: kindBoolean, description: "Encrypt paravirtualized volume traffic in transit."},
+	{env: "OCI_PLATFORM_TYPE", description: "Platform type for Shielded Instance options.", enum: platformTypes},
+	{env: "OCI_SECURE_BOOT", kind: kindBoolean, description: "Enable Secure Boot."},
+	{env: "OCI_MEASURED_BOOT", kind: kindBoolean, description: "Enable Measured Boot."},
+	{env: "OCI_TPM", kind: kindBoolean, description: "Enable the Trusted Platform Module."},
+	{env: "OCI_DISABLE_LEGACY_IMDS", kind: kindBoolean, description: "Disable the legacy IMDS v1 endpoints."},
+	{env: "OCI_RECOVERY_ACTION", description: "Action after a maintenance event.", enum: recoveryActions},
+	{env: "OCI_LIVE_MIGRATION_PREFERRED", kind: kindBoolean, description: "Prefer live migration during maintenance."},
+	{env: "OCI_AGENT_MONITORING_DISABLED", kind: kindBoolean, description: "Disable Oracle Cloud Agent monitoring plugins."},
+	{env: "OCI_AGENT_MANAGEMENT_DISABLED", kind: kindBoolean, description: "Disable Oracle Cloud Agent management plugins."},
+	{env: "OCI_AGENT_ALL_PLUGINS_DISABLED", kind: kindBoolean, description: "Disable all Oracle Cloud Agent plugins."},
+	{env: "OCI_AGENT_PLUGINS", kind: kindPairs, description: "Desired state of Oracle Cloud Agent plugins by name, ENABLED or DISABLED."},
+	{env: "OCI_SCAN_STRATEGY", description: "How availability domains are probed.", enum: []string{ScanStrategyLaunch, ScanStrategyReport}},
+	{env: "OCI_CAPACITY_RESERVATION_ID", description: "Capacity reservation to launch from."},
+	{env: "OCI_CAPACITY_RESERVATION_MODE", kind: kindBoolean, description: "Hunt for reservation capacity, then launch from the reservation."},
+	{env: "OCI_LIMITS_CHECK_INTERVAL_SECONDS", kind: kindInteger, description: "How long to cache service limit checks, in seconds. 0 disables them."},
+	{env: "OCI_WATCH_MODE", kind: kindBoolean, description: "Keep running and re-provision lost instances."},
+	{env: "OCI_WATCH_INTERVAL_SECONDS", kind: kindInteger, description: "How often to re-check instances in watch mode, in seconds."},
+	{env: "OCI_KEEPALIVE_CHECK_HOURS", kind: kindInteger, description: "How often to check instance utilization in watch mode, in hours."},
+	{env: "OCI_KEEPALIVE_WARN_PERCENT", kind: kindInteger, description: "Warn when utilization is below this percentage."},
+	{env: "OCI_JSON_LOG_PATH", description: "File to log OCI API errors and reports to as JSON."},
+	{env: "BACKOFF_INITIAL_SECONDS", kind: kindInteger, description: "Initial wait after a Too Many Requests error, in seconds."},
+	{env: "BACKOFF_MAX_SECONDS", kind: kindInteger, description: "Maximum backoff wait, in seconds."},
+	{env: "TELEGRAM_BOT_API_KEY", description: "Telegram bot API key for notifications."},
+	{env: "TELEGRAM_USER_ID", description: "Telegram user or chat ID to notify."},
+	{env: "TELEGRAM_API_URL", description: "Telegram Bot API base URL."},
+	{env: "TELEGRAM_BOT_ENABLED", kind: kindBoolean, description: "Answer Telegram bot commands."},
+}
+
+// settingsByFileKey indexes settings by their config file name.
+var settingsByFileKey = func() map[string]setting {
+	byKey := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		byKey[s.fileKey()] = s
+	}
+	return byKey
+}()
+
+// Schema returns the JSON Schema of config files.
+func Schema() ([]byte, error) {
+	properties := make(map[string]any, len(settings))
+	for _, s := range settings {
+		properties[s.fileKey()] = s.schema()
+	}
+
+	topLevel := make(map[string]any, len(properties)+1)
+	for key, value := range properties {
+		topLevel[key] = value
+	}
+	topLevel["targets"] = map[string]any{
+		"type":                 "object",
+		"description":          "Named targets, each overriding the top-level settings.",
+		"additionalProperties": map[string]any{"$ref": "#/$defs/target"},
+	}
+
+	schema := map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                "oahc-go configuration",
+		"type":                 "object",
+		"properties":           topLevel,
+		"additionalProperties": false,
+		"$defs": map[string]any{
+			"target": map[string]any{
+				"type":                 "object",
+				"properties":           properties,
+				"additionalProperties": false,
+			},
+		},
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func (s setting) schema() map[string]any {
+	var schema map[string]any
+	switch s.kind {
+	case kindList:
+		items := map[string]any{"type": "string"}
+		if s.enum != nil {
+			items["enum"] = s.enum
+		}
+		schema = map[string]any{"type": "array", "items": items}
+	case kindPairs, kindStringObject:
+		schema = map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}
+	case kindNamespacedPairs:
+		schema = map[string]any{"type": "object", "additionalProperties": map[string]any{
+			"type": "object", "additionalProperties": map[string]any{"type": "string"},
+		}}
+	case kindObject:
+		schema = map[string]any{"type": "object"}
+	case kindObjectList:
+		properties := make(map[string]any, len(s.fields))
+		var required []string
+		for _, f := range s.fields {
+			properties[f.name] = scalarSchema(f.kind, f.enum)
+			if f.required {
+				required = append(required, f.name)
+			}
+		}
+		items := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+		if required != nil {
+			items["required"] = required
+		}
+		schema = map[string]any{"type": "array", "items": items}
+	default:
+		schema = scalarSchema(s.kind, s.enum)
+	}
+	schema["description"] = s.description
+	return schema
+}
+
+func scalarSchema(kind settingKind, enum []string) map[string]any {
+	schema := map[string]any{}
+	switch kind {
+	case kindInteger:
+		schema["type"] = "integer"
+	case kindBoolean:
+		schema["type"] = "boolean"
+	default:
+		schema["type"] = "string"
+	}
+	if enum != nil {
+		schema["enum"] = enum
+	}
+	return schema
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Ampere A1 shape bounds, as defined by OCI Compute.
+const (
+	a1Shape                = "VM.Standard.A1.Flex"
+	a1MaxOCPUs             = 80
+	a1MaxMemoryInGBs       = 512
+	a1MinMemoryPerOCPUInGB = 1
+	a1MaxMemoryPerOCPUInGB = 64
+)
+
+// validateShape checks the instance sizes and boot volume size.
+func (c *Config) validateShape() error {
+	var errs []error
+
+	// Fixed shapes ignore the size, flexible ones need a sensible one.
+	if strings.HasSuffix(c.Shape, ".Flex") {
+		if err := c.validateSize(ShapeSize{OCPUs: c.OCPUs, MemoryInGBs: c.MemoryInGBs}); err != nil {
+			errs = append(errs, fmt.Errorf("OCI_OCPUS/OCI_MEMORY_IN_GBS: %w", err))
+		}
+		for _, size := range c.ShapeFallbacks {
+			if err := c.validateSize(size); err != nil {
+				errs = append(errs, fmt.Errorf("OCI_SHAPE_FALLBACKS: %w", err))
+			}
+		}
+	}
+
+	if c.BootVolumeSizeGbs != 0 && (c.BootVolumeSizeGbs < minVolumeSizeGBs || c.BootVolumeSizeGbs > maxVolumeSizeGBs) {
+		errs = append(errs, fmt.Errorf("OCI_BOOT_VOLUME_SIZE_IN_GBS must be between %d and %d, got %d", minVolumeSizeGBs, maxVolumeSizeGBs, c.BootVolumeSizeGbs))
+	}
+	return errors.Join(errs...)
+}
+
+// validateSize checks an OCPU/memory combination for the configured shape.
+func (c *Config) validateSize(size ShapeSize) error {
+	if size.OCPUs < 1 || size.MemoryInGBs < 1 {
+		return fmt.Errorf("%s: OCPUs and memory must be positive", size)
+	}
+	if c.Shape != a1Shape {
+		return nil
+	}
+	if size.OCPUs > a1MaxOCPUs || size.MemoryInGBs > a1MaxMemoryInGBs {
+		return fmt.Errorf("%s: %s allows at most %d OCPUs and %d GB", size, a1Shape, a1MaxOCPUs, a1MaxMemoryInGBs)
+	}
+	if size.MemoryInGBs < size.OCPUs*a1MinMemoryPerOCPUInGB || size.MemoryInGBs > size.OCPUs*a1MaxMemoryPerOCPUInGB {
+		return fmt.Errorf("%s: %s needs %d to %d GB of memory per OCPU", size, a1Shape, a1MinMemoryPerOCPUInGB, a1MaxMemoryPerOCPUInGB)
+	}
+	return nil
+}
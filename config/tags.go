@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// TagVars are the values available to tag templates, e.g. "{{.AvailabilityDomain}}".
+type TagVars struct {
+	LaunchTime         string // RFC 3339, UTC
+	AvailabilityDomain string
+	DisplayName        string
+	Shape              string
+	Hostname           string // Host running the finder
+}
+
+// InstanceTags renders the configured freeform and defined tags for a
+// launch. The OCI_COUNT_TAG tag is always included so that launched
+// instances are counted.
+func (c *Config) InstanceTags(vars TagVars) (map[string]string, map[string]map[string]interface{}, error) {
+	freeform := make(map[string]string, len(c.FreeformTags)+1)
+	for key, tmpl := range c.FreeformTags {
+		val, err := renderTag(tmpl, vars)
+		if err != nil {
+			return nil, nil, fmt.Errorf("freeform tag %s: %w", key, err)
+		}
+		freeform[key] = val
+	}
+	if c.CountTagKey != "" {
+		freeform[c.CountTagKey] = c.CountTagValue
+	}
+
+	var defined map[string]map[string]interface{}
+	for namespace, tags := range c.DefinedTags {
+		if defined == nil {
+			defined = make(map[string]map[string]interface{})
+		}
+		defined[namespace] = make(map[string]interface{}, len(tags))
+		for key, tmpl := range tags {
+			val, err := renderTag(tmpl, vars)
+			if err != nil {
+				return nil, nil, fmt.Errorf("defined tag %s.%s: %w", namespace, key, err)
+			}
+			defined[namespace][key] = val
+		}
+	}
+	return freeform, defined, nil
+}
+
+// validateTags checks that every tag template renders, in key order. The
+// templates are executed with empty values, so that unknown fields are
+// reported here rather than on every launch.
+func (c *Config) validateTags() error {
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(c.FreeformTags)) {
+		if _, err := renderTag(c.FreeformTags[key], TagVars{}); err != nil {
+			errs = append(errs, fmt.Errorf("invalid template in OCI_FREEFORM_TAGS for %s: %w", key, err))
+		}
+	}
+	for _, namespace := range slices.Sorted(maps.Keys(c.DefinedTags)) {
+		tags := c.DefinedTags[namespace]
+		for _, key := range slices.Sorted(maps.Keys(tags)) {
+			if _, err := renderTag(tags[key], TagVars{}); err != nil {
+				errs = append(errs, fmt.Errorf("invalid template in OCI_DEFINED_TAGS for %s.%s: %w", namespace, key, err))
+			}
+		}
+	}
+	if strings.Contains(c.CountTagValue, "{{") {
+		errs = append(errs, fmt.Errorf("OCI_COUNT_TAG must be a fixed key=value, not a template"))
+	}
+	return errors.Join(errs...)
+}
+
+// renderTag executes a tag value template.
+func renderTag(tmpl string, vars TagVars) (string, error) {
+	if !strings.Contains(tmpl, "{{") {
+		return tmpl, nil
+	}
+	t, err := template.New("tag").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, vars); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// keyValue is one key=value pair of a setting, in the order it was given.
+type keyValue struct {
+	key, value string
+}
+
+// parsePairs parses a comma-separated list of key=value pairs.
+func parsePairs(val string) ([]keyValue, error) {
+	var pairs []keyValue
+	for _, item := range splitList(val) {
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%q is not in the form key=value", item)
+		}
+		pairs = append(pairs, keyValue{key: key, value: strings.TrimSpace(value)})
+	}
+	return pairs, nil
+}
+
+// pairsMap returns pairs as a map; later pairs override earlier ones.
+func pairsMap(pairs []keyValue) map[string]string {
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		m[pair.key] = pair.value
+	}
+	return m
+}
+
+// parseDefinedTags parses a comma-separated list of namespace.key=value pairs.
+func parseDefinedTags(val string) (map[string]map[string]string, error) {
+	pairs, err := parsePairs(val)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]map[string]string)
+	for _, pair := range pairs {
+		namespace, key, ok := strings.Cut(pair.key, ".")
+		if !ok || namespace == "" || key == "" {
+			return nil, fmt.Errorf("%q is not in the form namespace.key=value", pair.key)
+		}
+		if tags[namespace] == nil {
+			tags[namespace] = make(map[string]string)
+		}
+		tags[namespace][key] = pair.value
+	}
+	return tags, nil
+}
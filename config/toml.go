@@ -0,0 +1,578 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// tomlNode is a value parsed from a TOML document, with the line it starts on.
+type tomlNode struct {
+	line  int
+	value any // string, int64, float64, bool, []*tomlNode or tomlTable
+}
+
+// tomlTable is a TOML table, mapping keys to values.
+type tomlTable map[string]*tomlNode
+
+// FileError is an error at a line of a config file.
+type FileError struct {
+	Path string
+	Line int
+	Msg  string
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Msg)
+}
+
+// tomlParser parses the subset of TOML 1.0 needed for config files: tables,
+// arrays of tables, dotted and quoted keys, strings (including multiline),
+// integers, floats, booleans, arrays and inline tables. Dates and times are
+// not supported.
+type tomlParser struct {
+	path string
+	src  string
+	pos  int
+	line int
+
+	root    *tomlNode
+	current *tomlNode
+	// explicit holds tables defined by a [header], which may not be defined again.
+	explicit map[*tomlNode]bool
+	// tableArrays holds arrays created by [[header]], which may be appended to.
+	tableArrays map[*tomlNode]bool
+}
+
+// parseTOML parses a TOML document. Errors carry the path and line.
+func parseTOML(path, src string) (tomlTable, error) {
+	root := &tomlNode{line: 1, value: tomlTable{}}
+	p := &tomlParser{
+		path:        path,
+		src:         src,
+		line:        1,
+		root:        root,
+		current:     root,
+		explicit:    make(map[*tomlNode]bool),
+		tableArrays: make(map[*tomlNode]bool),
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return root.value.(tomlTable), nil
+}
+
+func (p *tomlParser) errorf(format string, args ...any) error {
+	return &FileError{Path: p.path, Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *tomlParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+// advance consumes n bytes, counting newlines.
+func (p *tomlParser) advance(n int) {
+	for i := 0; i < n && !p.eof(); i++ {
+		if p.src[p.pos] == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+// skipSpace skips spaces and tabs.
+func (p *tomlParser) skipSpace() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+// skipComment skips a comment up to, but not including, the end of the line.
+func (p *tomlParser) skipComment() {
+	if p.peek() != '#' {
+		return
+	}
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+// skipBlank skips whitespace, newlines and comments.
+func (p *tomlParser) skipBlank() {
+	for {
+		p.skipSpace()
+		p.skipComment()
+		switch {
+		case p.hasPrefix("\r\n"):
+			p.advance(2)
+		case p.peek() == '\n':
+			p.advance(1)
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine consumes an optional comment and the line break that must
+// follow a key/value pair or table header.
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	p.skipComment()
+	switch {
+	case p.eof():
+		return nil
+	case p.hasPrefix("\r\n"):
+		p.advance(2)
+		return nil
+	case p.peek() == '\n':
+		p.advance(1)
+		return nil
+	}
+	return p.errorf("expected end of line, found %q", p.peek())
+}
+
+func (p *tomlParser) parse() error {
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return nil
+		}
+
+		var err error
+		switch {
+		case p.hasPrefix("[["):
+			err = p.parseTableArrayHeader()
+		case p.peek() == '[':
+			err = p.parseTableHeader()
+		default:
+			err = p.parseKeyValue(p.current)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseTableHeader parses "[a.b]" and makes that table current.
+func (p *tomlParser) parseTableHeader() error {
+	line := p.line
+	p.advance(1)
+	p.skipSpace()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return p.errorf("expected ] to close the table header")
+	}
+	p.advance(1)
+
+	parent, err := p.walk(p.root, keys[:len(keys)-1], line)
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	table := parent.value.(tomlTable)
+	node, ok := table[last]
+	switch {
+	case !ok:
+		node = &tomlNode{line: line, value: tomlTable{}}
+		table[last] = node
+	case p.explicit[node]:
+		return p.errorf("table [%s] is defined more than once", strings.Join(keys, "."))
+	default:
+		if _, isTable := node.value.(tomlTable); !isTable {
+			return p.errorf("key %q is already defined as a value", strings.Join(keys, "."))
+		}
+	}
+	p.explicit[node] = true
+	p.current = node
+	return nil
+}
+
+// parseTableArrayHeader parses "[[a.b]]", appending a new table to the array.
+func (p *tomlParser) parseTableArrayHeader() error {
+	line := p.line
+	p.advance(2)
+	p.skipSpace()
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if !p.hasPrefix("]]") {
+		return p.errorf("expected ]] to close the table array header")
+	}
+	p.advance(2)
+
+	parent, err := p.walk(p.root, keys[:len(keys)-1], line)
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	table := parent.value.(tomlTable)
+	node, ok := table[last]
+	if !ok {
+		node = &tomlNode{line: line, value: []*tomlNode{}}
+		table[last] = node
+		p.tableArrays[node] = true
+	} else if !p.tableArrays[node] {
+		return p.errorf("key %q is already defined and is not an array of tables", strings.Join(keys, "."))
+	}
+	element := &tomlNode{line: line, value: tomlTable{}}
+	node.value = append(node.value.([]*tomlNode), element)
+	p.current = element
+	return nil
+}
+
+// walk descends from node through keys, creating missing tables. Arrays of
+// tables are entered through their last element.
+func (p *tomlParser) walk(node *tomlNode, keys []string, line int) (*tomlNode, error) {
+	for i, key := range keys {
+		table := node.value.(tomlTable)
+		next, ok := table[key]
+		if !ok {
+			next = &tomlNode{line: line, value: tomlTable{}}
+			table[key] = next
+		}
+		switch value := next.value.(type) {
+		case tomlTable:
+		case []*tomlNode:
+			if !p.tableArrays[next] || len(value) == 0 {
+				return nil, p.errorf("key %q is not a table", strings.Join(keys[:i+1], "."))
+			}
+			next = value[len(value)-1]
+		default:
+			return nil, p.errorf("key %q is already defined as a value", strings.Join(keys[:i+1], "."))
+		}
+		node = next
+	}
+	return node, nil
+}
+
+// parseKeyValue parses "key = value" into the given table.
+func (p *tomlParser) parseKeyValue(into *tomlNode) error {
+	line := p.line
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace()
+	if p.peek() != '=' {
+		return p.errorf("expected = after key %q", strings.Join(keys, "."))
+	}
+	p.advance(1)
+	p.skipSpace()
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	parent, err := p.walk(into, keys[:len(keys)-1], line)
+	if err != nil {
+		return err
+	}
+	table := parent.value.(tomlTable)
+	last := keys[len(keys)-1]
+	if _, ok := table[last]; ok {
+		return &FileError{Path: p.path, Line: line, Msg: fmt.Sprintf("key %q is defined more than once", strings.Join(keys, "."))}
+	}
+	table[last] = value
+	return nil
+}
+
+// parseKey parses a possibly dotted key.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		key, err := p.parseSimpleKey()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+		p.skipSpace()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.advance(1)
+		p.skipSpace()
+	}
+}
+
+func (p *tomlParser) parseSimpleKey() (string, error) {
+	switch p.peek() {
+	case '"':
+		return p.parseBasicString()
+	case '\'':
+		return p.parseLiteralString()
+	}
+	start := p.pos
+	for !p.eof() && isBareKeyChar(p.peek()) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a key, found %q", p.peek())
+	}
+	return p.src[start:p.pos], nil
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) parseValue() (*tomlNode, error) {
+	line := p.line
+	var value any
+	var err error
+	switch c := p.peek(); {
+	case p.hasPrefix(`"""`):
+		value, err = p.parseMultilineString(`"""`, true)
+	case c == '"':
+		value, err = p.parseBasicString()
+	case p.hasPrefix("'''"):
+		value, err = p.parseMultilineString("'''", false)
+	case c == '\'':
+		value, err = p.parseLiteralString()
+	case p.hasPrefix("true"):
+		p.advance(4)
+		value = true
+	case p.hasPrefix("false"):
+		p.advance(5)
+		value = false
+	case c == '[':
+		value, err = p.parseArray()
+	case c == '{':
+		value, err = p.parseInlineTable()
+	case c == '+' || c == '-' || c >= '0' && c <= '9' || p.hasPrefix("inf") || p.hasPrefix("nan"):
+		value, err = p.parseNumber()
+	case p.eof() || c == '\n' || c == '\r' || c == '#':
+		err = p.errorf("missing value")
+	default:
+		err = p.errorf("invalid value starting with %q", c)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tomlNode{line: line, value: value}, nil
+}
+
+func (p *tomlParser) parseNumber() (any, error) {
+	start := p.pos
+	for !p.eof() && strings.IndexByte("0123456789abcdefABCDEFxob_+-.ni", p.peek()) >= 0 {
+		p.pos++
+	}
+	text := p.src[start:p.pos]
+	if strings.ContainsAny(p.src[p.pos:min(p.pos+1, len(p.src))], ":T") {
+		return nil, p.errorf("dates and times are not supported")
+	}
+	clean := strings.ReplaceAll(text, "_", "")
+
+	isHex := strings.HasPrefix(clean, "0x") || strings.HasPrefix(clean, "0o") || strings.HasPrefix(clean, "0b")
+	// TOML has no octal without 0o; a leading zero is an error, not base 8.
+	if digits := strings.TrimLeft(clean, "+-"); len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9' {
+		return nil, p.errorf("invalid number %q: leading zeros are not allowed", text)
+	}
+	if !isHex && strings.ContainsAny(clean, ".eEin") {
+		f, err := strconv.ParseFloat(strings.TrimPrefix(clean, "+"), 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", text)
+		}
+		return f, nil
+	}
+	n, err := strconv.ParseInt(clean, 0, 64)
+	if err != nil {
+		return nil, p.errorf("invalid integer %q", text)
+	}
+	return n, nil
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.advance(1)
+	var sb strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.peek()
+		switch c {
+		case '"':
+			p.advance(1)
+			return sb.String(), nil
+		case '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+			p.advance(1)
+		}
+	}
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.advance(1)
+	start := p.pos
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+		if p.peek() == '\'' {
+			s := p.src[start:p.pos]
+			p.advance(1)
+			return s, nil
+		}
+		p.pos++
+	}
+}
+
+// parseMultilineString parses a multiline basic or literal string, delimited
+// by triple quotes. A newline right after the opening delimiter is trimmed.
+func (p *tomlParser) parseMultilineString(delim string, escapes bool) (string, error) {
+	p.advance(3)
+	if p.hasPrefix("\r\n") {
+		p.advance(2)
+	} else if p.peek() == '\n' {
+		p.advance(1)
+	}
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multiline string")
+		}
+		if p.hasPrefix(delim) {
+			// Up to two quotes may directly precede the closing delimiter.
+			for i := 0; i < 2 && p.hasPrefix(delim+delim[:1]); i++ {
+				sb.WriteByte(delim[0])
+				p.advance(1)
+			}
+			p.advance(3)
+			return sb.String(), nil
+		}
+		if escapes && p.peek() == '\\' {
+			// A backslash at the end of a line trims the line break and
+			// leading whitespace of the next line.
+			rest := strings.TrimLeft(p.src[p.pos+1:], " \t")
+			if strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n") {
+				p.advance(1)
+				for strings.IndexByte(" \t\r\n", p.peek()) >= 0 && !p.eof() {
+					p.advance(1)
+				}
+				continue
+			}
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+			continue
+		}
+		sb.WriteByte(p.peek())
+		p.advance(1)
+	}
+}
+
+func (p *tomlParser) parseEscape(sb *strings.Builder) error {
+	p.advance(1)
+	c := p.peek()
+	p.advance(1)
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return p.errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid unicode escape \\%c%s", c, p.src[p.pos:p.pos+size])
+		}
+		sb.WriteRune(rune(code))
+		p.advance(size)
+	default:
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	return nil
+}
+
+func (p *tomlParser) parseArray() ([]*tomlNode, error) {
+	p.advance(1)
+	items := []*tomlNode{}
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.advance(1)
+			return items, nil
+		}
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.advance(1)
+		case ']':
+		default:
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (tomlTable, error) {
+	p.advance(1)
+	node := &tomlNode{line: p.line, value: tomlTable{}}
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.advance(1)
+		return node.value.(tomlTable), nil
+	}
+	for {
+		p.skipSpace()
+		if err := p.parseKeyValue(node); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.advance(1)
+		case '}':
+			p.advance(1)
+			return node.value.(tomlTable), nil
+		default:
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// plain converts a parsed table to plain Go values for comparison.
+func plain(table tomlTable) any {
+	return plainValue(&tomlNode{value: table})
+}
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string]any
+	}{
+		{
+			name: "scalars",
+			src:  "a = \"x\"\nb = 'y'\nc = 42\nd = -1_000\ne = true\nf = 1.5\ng = 0x1f\nh = 0",
+			want: map[string]any{"a": "x", "b": "y", "c": int64(42), "d": int64(-1000), "e": true, "f": 1.5, "g": int64(31), "h": int64(0)},
+		},
+		{
+			name: "comments and escapes",
+			src:  "# comment\na = \"tab\\there\\u00e9\" # trailing\n",
+			want: map[string]any{"a": "tab\thereé"},
+		},
+		{
+			name: "multiline strings",
+			src:  "a = \"\"\"\nline one\nline two\"\"\"\nb = '''\nraw \\n'''",
+			want: map[string]any{"a": "line one\nline two", "b": "raw \\n"},
+		},
+		{
+			name: "arrays",
+			src:  "a = [\n  \"x\", # first\n  \"y\",\n]\nb = [1, 2]",
+			want: map[string]any{"a": []any{"x", "y"}, "b": []any{int64(1), int64(2)}},
+		},
+		{
+			name: "tables and dotted keys",
+			src:  "a.b = 1\n[t]\nc = 2\n[t.u]\n\"quoted key\" = 3\nv = { w = 4 }",
+			want: map[string]any{
+				"a": map[string]any{"b": int64(1)},
+				"t": map[string]any{"c": int64(2), "u": map[string]any{"quoted key": int64(3), "v": map[string]any{"w": int64(4)}}},
+			},
+		},
+		{
+			name: "arrays of tables",
+			src:  "[[r]]\nx = 1\n[[r]]\nx = 2",
+			want: map[string]any{"r": []any{map[string]any{"x": int64(1)}, map[string]any{"x": int64(2)}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := parseTOML("test.toml", tt.src)
+			if err != nil {
+				t.Fatalf("parseTOML: %v", err)
+			}
+			if got := plain(table); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTOMLNodeLines(t *testing.T) {
+	table, err := parseTOML("test.toml", "a = 1\n\n[t]\nb = [\n  \"x\",\n  \"y\",\n]")
+	if err != nil {
+		t.Fatalf("parseTOML: %v", err)
+	}
+	if line := table["a"].line; line != 1 {
+		t.Errorf("a on line %d, want 1", line)
+	}
+	b := table["t"].value.(tomlTable)["b"]
+	if b.line != 4 {
+		t.Errorf("b on line %d, want 4", b.line)
+	}
+	if line := b.value.([]*tomlNode)[1].line; line != 6 {
+		t.Errorf("b[1] on line %d, want 6", line)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		line int
+	}{
+		{"leading zero", "a = 1\nb = 012", 2},
+		{"leading zero with sign", "a = -07", 1},
+		{"leading zero float", "a = 01.5", 1},
+		{"unterminated string", "a = \"x\nb = 1", 1},
+		{"duplicate key", "a = 1\n\na = 2", 3},
+		{"duplicate table", "[t]\n[u]\n[t]", 3},
+		{"missing value", "a =", 1},
+		{"trailing garbage", "a = 1 2", 1},
+		{"date", "a = 2024-01-01", 1},
+		{"unterminated array", "a = [\n1,\n", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML("test.toml", tt.src)
+			var fileErr *FileError
+			if !errors.As(err, &fileErr) {
+				t.Fatalf("got %v, want a *FileError", err)
+			}
+			if fileErr.Path != "test.toml" || fileErr.Line != tt.line {
+				t.Errorf("got %v, want test.toml:%d", err, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+)
+
+// maxNsgsPerVnic is the number of network security groups OCI allows on a VNIC.
+const maxNsgsPerVnic = 5
+
+// hostnameLabelPattern matches an RFC 952/1123 hostname label as accepted by OCI.
+var hostnameLabelPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{0,62}$`)
+
+// validateVnic checks the VNIC settings that can be verified without calling OCI.
+// Checks against the subnet itself happen in the startup preflight.
+func (c *Config) validateVnic() error {
+	var errs []error
+	if len(c.NsgIDs) > maxNsgsPerVnic {
+		errs = append(errs, fmt.Errorf("OCI_NSG_IDS lists %d network security groups, but a VNIC supports at most %d", len(c.NsgIDs), maxNsgsPerVnic))
+	}
+
+	if c.PrivateIP != "" {
+		addr, err := netip.ParseAddr(c.PrivateIP)
+		if err != nil || !addr.Is4() {
+			errs = append(errs, fmt.Errorf("OCI_PRIVATE_IP %q is not a valid IPv4 address", c.PrivateIP))
+		}
+		if c.MaxInstances > 1 {
+			errs = append(errs, fmt.Errorf("OCI_PRIVATE_IP cannot be used with OCI_MAX_INSTANCES greater than 1"))
+		}
+	}
+
+	if c.HostnameLabel != "" {
+		if !hostnameLabelPattern.MatchString(c.HostnameLabel) {
+			errs = append(errs, fmt.Errorf("OCI_HOSTNAME_LABEL %q must start with a letter and contain only letters, digits and hyphens (max 63)", c.HostnameLabel))
+		}
+		if c.MaxInstances > 1 {
+			errs = append(errs, fmt.Errorf("OCI_HOSTNAME_LABEL cannot be used with OCI_MAX_INSTANCES greater than 1"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Block volume attachment types.
+const (
+	AttachmentTypeParavirtualized = "paravirtualized"
+	AttachmentTypeISCSI           = "iscsi"
+)
+
+// Block volume size and performance bounds, as defined by OCI Block Volume.
+const (
+	minVolumeSizeGBs = 50
+	maxVolumeSizeGBs = 32768
+	maxVpusPerGB     = 120
+)
+
+// Always Free block storage, shared by all boot and block volumes, and the
+// boot volume size of platform images when none is configured.
+const (
+	freeStorageGBs           = 200
+	defaultBootVolumeSizeGBs = 47
+)
+
+// BlockVolume describes a block volume to attach to new instances, either an
+// existing volume or one created in the instance's availability domain.
+type BlockVolume struct {
+	VolumeID       string `json:"volumeId,omitempty"`       // Existing volume; must be in the instance's AD
+	DisplayName    string `json:"displayName,omitempty"`    // For created volumes
+	SizeInGBs      int    `json:"sizeInGBs,omitempty"`      // For created volumes
+	VpusPerGB      *int   `json:"vpusPerGB,omitempty"`      // For created volumes; 0 is Lower Cost, 10 Balanced
+	AttachmentType string `json:"attachmentType,omitempty"` // paravirtualized (default) or iscsi
+	ReadOnly       bool   `json:"readOnly,omitempty"`
+}
+
+// blockVolumeFromTable converts an OCI_BLOCK_VOLUMES table from a config
+// file, whose values have been checked against blockVolumeFields.
+func blockVolumeFromTable(table map[string]any) BlockVolume {
+	volume := BlockVolume{}
+	volume.VolumeID, _ = table["volumeId"].(string)
+	volume.DisplayName, _ = table["displayName"].(string)
+	volume.SizeInGBs, _ = table["sizeInGBs"].(int)
+	if vpus, ok := table["vpusPerGB"].(int); ok {
+		volume.VpusPerGB = &vpus
+	}
+	volume.AttachmentType, _ = table["attachmentType"].(string)
+	volume.ReadOnly, _ = table["readOnly"].(bool)
+	return volume
+}
+
+// validateBlockVolumes checks each OCI_BLOCK_VOLUMES entry.
+func (c *Config) validateBlockVolumes() error {
+	var errs []error
+	for i := range c.BlockVolumes {
+		volume := c.BlockVolumes[i]
+		name := fmt.Sprintf("OCI_BLOCK_VOLUMES[%d]", i)
+
+		if volume.AttachmentType != AttachmentTypeParavirtualized && volume.AttachmentType != AttachmentTypeISCSI {
+			errs = append(errs, fmt.Errorf("%s: attachmentType must be %q or %q", name, AttachmentTypeParavirtualized, AttachmentTypeISCSI))
+		}
+
+		if volume.VolumeID != "" {
+			if volume.SizeInGBs != 0 || volume.VpusPerGB != nil {
+				errs = append(errs, fmt.Errorf("%s: sizeInGBs and vpusPerGB cannot be used with volumeId", name))
+			}
+			if c.MaxInstances > 1 {
+				errs = append(errs, fmt.Errorf("%s: an existing volume can only be attached with OCI_MAX_INSTANCES=1", name))
+			}
+			continue
+		}
+
+		if volume.SizeInGBs < minVolumeSizeGBs || volume.SizeInGBs > maxVolumeSizeGBs {
+			errs = append(errs, fmt.Errorf("%s: sizeInGBs must be between %d and %d, or set volumeId", name, minVolumeSizeGBs, maxVolumeSizeGBs))
+		}
+		if vpus := volume.VpusPerGB; vpus != nil && (*vpus < 0 || *vpus > maxVpusPerGB || *vpus%10 != 0) {
+			errs = append(errs, fmt.Errorf("%s: vpusPerGB must be a multiple of 10 between 0 and %d", name, maxVpusPerGB))
+		}
+	}
+
+	if len(c.BlockVolumes) > 0 && !c.AllowPaid {
+		if size := c.instanceStorageGBs() * c.MaxInstances; size > freeStorageGBs {
+			errs = append(errs, fmt.Errorf("the boot and created block volumes of OCI_MAX_INSTANCES=%d instances need %d GB, more than the %d GB of Always Free block storage; set OCI_ALLOW_PAID=true to allow it", c.MaxInstances, size, freeStorageGBs))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// instanceStorageGBs returns the block storage each launched instance
+// creates: its boot volume, unless an existing one is used, and the block
+// volumes created for it. A boot volume of unknown size counts as the
+// default size of platform images.
+func (c *Config) instanceStorageGBs() int {
+	size := 0
+	switch {
+	case c.BootVolumeID != "":
+	case c.BootVolumeSizeGbs > 0:
+		size = c.BootVolumeSizeGbs
+	default:
+		size = defaultBootVolumeSizeGBs
+	}
+	for _, volume := range c.BlockVolumes {
+		if volume.VolumeID == "" {
+			size += volume.SizeInGBs
+		}
+	}
+	return size
+}
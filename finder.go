@@ -0,0 +1,540 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/idanyas/oahc-go/backoff"
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/notifier"
+	"github.com/idanyas/oahc-go/oci"
+	"github.com/idanyas/oahc-go/state"
+)
+
+// launchOutcome summarises the result of trying to launch in one availability domain.
+type launchOutcome int
+
+const (
+	launchOutOfCapacity launchOutcome = iota
+	launchBackoff
+	launchCreated
+)
+
+// finder runs the capacity-hunting loop.
+type finder struct {
+	cfg        *config.Config
+	client     *oci.Client
+	backoff    *backoff.Manager
+	state      *state.State
+	tgNotifier *notifier.TelegramNotifier
+	watcher    *instanceWatcher
+	watching   bool
+
+	// downsized holds instances this process launched below the preferred size.
+	downsized  map[string]bool
+	lastResize time.Time
+
+	limits    *limitsChecker
+	keepAlive keepAliveChecker
+
+	// regions are the regions to scan, the primary (OCI_REGION) first. cfg,
+	// client, backoff and limits belong to current, the region in use.
+	regions         []*region
+	current         *region
+	instanceRegions map[string]*region
+
+	// home is the tenancy's home region, the only one with the Always Free
+	// allowance. It need not be scanned.
+	home *region
+
+	// compartments is the compartment subtree counted with OCI_COUNT_SUBTREE.
+	compartments []string
+	// tenancyCompartments are all compartments of the tenancy, root first,
+	// whose A1 instances are charged against the free-tier budget.
+	tenancyCompartments []string
+	// freeTierOthers are the A1 instances in the home region outside the
+	// cycle's listing, as of freeTierListed.
+	freeTierOthers []oci.Instance
+	freeTierListed time.Time
+
+	// reservation is the capacity reservation instances are launched from, if any.
+	reservation *oci.ComputeCapacityReservation
+
+	// restored maps availability domains to boot volumes restored from
+	// OCI_BOOT_VOLUME_BACKUP_ID that have not been launched from yet.
+	restored map[string]string
+}
+
+func newFinder(cfg *config.Config, client *oci.Client, st *state.State, tgNotifier *notifier.TelegramNotifier) *finder {
+	f := &finder{
+		state:           st,
+		tgNotifier:      tgNotifier,
+		watcher:         newInstanceWatcher(),
+		downsized:       make(map[string]bool),
+		restored:        make(map[string]string),
+		instanceRegions: make(map[string]*region),
+	}
+	f.regions = []*region{newRegion(cfg, client)}
+	f.home = f.regions[0]
+	f.use(f.regions[0])
+	return f
+}
+
+// run loops until the target is reached (outside watch mode) or a stop is requested.
+func (f *finder) run() {
+	defer f.cleanupRestoredBootVolumes()
+	for f.cycle() {
+	}
+}
+
+// cycle performs one list-and-launch pass. It returns false when the finder should exit.
+func (f *finder) cycle() bool {
+	if !f.state.WaitWhilePaused() {
+		log.Println("Stop requested. Exiting.")
+		return false
+	}
+	if wait := f.backoffWait(); wait > 0 {
+		f.state.SetPhase("backing off")
+		return f.state.Sleep(wait)
+	}
+	f.state.StartCycle()
+	f.use(f.regions[0])
+
+	f.state.SetPhase("listing instances")
+	instances, err := f.listInstances()
+	if err != nil {
+		log.Printf("ERROR: Failed to list instances: %v. Retrying in 30s...", err)
+		f.state.RecordError()
+		return f.state.Sleep(30 * time.Second)
+	}
+	// A successful API call should reset the backoff state.
+	f.backoff.Reset()
+
+	existingInstances := 0
+	for _, instance := range instances {
+		if countsTowardTarget(instance, f.cfg) {
+			existingInstances++
+		}
+	}
+	f.state.SetInstances(instances, existingInstances)
+
+	if lost := f.watcher.update(instances, f.cfg); len(lost) > 0 {
+		message := lostInstancesMessage(lost, existingInstances, f.cfg.MaxInstances)
+		log.Println(message)
+		f.notify(message)
+	}
+
+	f.checkKeepAlive(instances)
+
+	if existingInstances >= f.cfg.MaxInstances {
+		f.cleanupRestoredBootVolumes()
+		f.resizeDownsized(instances)
+		if !f.keepRunning() {
+			log.Printf("Target instance count (%d) reached. Exiting.", f.cfg.MaxInstances)
+			return false
+		}
+
+		interval := time.Duration(f.cfg.ResizeIntervalSeconds) * time.Second
+		if f.cfg.WatchMode {
+			interval = time.Duration(f.cfg.WatchIntervalSeconds) * time.Second
+		}
+		if !f.watching {
+			log.Printf("Target instance count (%d) reached. Re-checking every %v.", f.cfg.MaxInstances, interval)
+			f.watching = true
+		}
+		f.state.SetPhase("watching")
+		return f.state.Sleep(interval)
+	}
+	if f.watching {
+		log.Printf("Instance count (%d) is below target (%d). Resuming capacity hunting.", existingInstances, f.cfg.MaxInstances)
+		f.watching = false
+	}
+
+	sizes := f.cfg.ShapeSizes()
+	var budget freeBudget
+	if f.cfg.Shape == freeTierShape {
+		var err error
+		budget, err = f.remainingFreeBudget(instances)
+		if err != nil {
+			log.Printf("ERROR: Failed to compute free-tier budget: %v. Retrying in 30s...", err)
+			f.state.RecordError()
+			return f.state.Sleep(30 * time.Second)
+		}
+		sizes = launchSizes(f.cfg, budget)
+		if len(sizes) == 0 {
+			log.Printf("No shape size fits the remaining free-tier budget (%s). Refusing to launch.", budget)
+			if !f.cfg.WatchMode {
+				return false
+			}
+			f.state.SetPhase("waiting for free-tier budget")
+			return f.state.Sleep(time.Duration(f.cfg.WatchIntervalSeconds) * time.Second)
+		}
+	}
+
+	if f.cfg.CapacityReservationMode || f.cfg.CapacityReservationID != "" {
+		return f.reservationCycle(sizes, f.cfg.MaxInstances-existingInstances, budget)
+	}
+
+	f.state.SetPhase("resolving availability domains")
+	schedule, err := f.scanSchedule()
+	if err != nil {
+		log.Printf("ERROR: Failed to get availability domains: %v. Retrying in 30s...", err)
+		f.state.RecordError()
+		return f.state.Sleep(30 * time.Second)
+	}
+	if len(schedule) == 0 {
+		interval := time.Duration(f.cfg.LimitsCheckIntervalSeconds) * time.Second
+		log.Printf("No availability domain has %s limits available. Re-checking in %v.", f.cfg.Shape, interval)
+		f.state.SetPhase("waiting for service limits")
+		return f.state.Sleep(interval)
+	}
+
+	// A region that hits Too Many Requests is skipped until its backoff
+	// period has passed, so that the other regions keep being scanned.
+	tmrHit := make(map[*region]bool)
+scan:
+	for _, target := range schedule {
+		if target.region.backingOff() {
+			continue
+		}
+		// Honour /pause and /stop between attempts, not only between cycles.
+		if f.state.Paused() {
+			break
+		}
+		select {
+		case <-f.state.Stopped():
+			log.Println("Stop requested. Exiting.")
+			return false
+		default:
+		}
+
+		f.use(target.region)
+		switch f.tryAvailabilityDomain(target.ad, sizes) {
+		case launchOutOfCapacity:
+			continue
+		case launchBackoff:
+			// Try this region again once its backoff period has passed.
+			tmrHit[target.region] = true
+		case launchCreated:
+			if !f.keepRunning() {
+				return false
+			}
+			// Re-list to pick up the new instance and keep going.
+			break scan
+		}
+	}
+
+	// After trying all ADs, reset the backoff of regions that hit no TMR.
+	for _, r := range f.regions {
+		if !tmrHit[r] {
+			r.backoff.Reset()
+		}
+	}
+	return true
+}
+
+// tryAvailabilityDomain attempts a launch in ad, stepping through sizes and
+// fault domains while the domain reports being out of capacity.
+func (f *finder) tryAvailabilityDomain(ad string, sizes []config.ShapeSize) launchOutcome {
+	placements := f.placements(sizes)
+	multiple := len(placements) > 1
+
+	if f.cfg.ScanStrategy == config.ScanStrategyReport {
+		f.state.SetPhase(fmt.Sprintf("checking capacity in %s", ad))
+		available, err := f.reportedPlacements(ad, placements)
+		switch {
+		case err != nil && isTooManyRequests(err):
+			log.Printf("Checking %s: Too Many Requests.", ad)
+			f.state.RecordTooManyRequests()
+			f.backOff(f.current)
+			return launchBackoff
+		case err != nil:
+			log.Printf("Checking %s: capacity report failed: %v. Trying to launch anyway.", ad, err)
+		case len(available) == 0:
+			log.Printf("Checking %s: Capacity report shows no capacity.", ad)
+			f.state.RecordSkipped(ad, "capacity report shows no capacity")
+			f.state.RecordOutOfCapacity()
+			f.backoff.Reset()
+			return launchOutOfCapacity
+		default:
+			placements = available
+		}
+	}
+
+	for _, p := range placements {
+		outcome := f.attemptLaunch(p.label(ad, multiple), p.size, oci.LaunchParams{
+			AvailabilityDomain: ad,
+			ShapeConfig:        shapeConfigOf(p.size),
+			FaultDomain:        p.faultDomain,
+		})
+		if outcome != launchOutOfCapacity {
+			return outcome
+		}
+	}
+	return launchOutOfCapacity
+}
+
+// launch creates an instance, either directly or from the configured
+// instance configuration, and waits for its launch work request, so that a
+// launch that fails after being accepted is reported as an error. Failing to
+// follow the work request does not fail the launch. With
+// OCI_BOOT_VOLUME_BACKUP_ID, the backup is first restored into the
+// availability domain.
+func (f *finder) launch(params oci.LaunchParams) (*oci.Instance, error) {
+	var (
+		instance      *oci.Instance
+		workRequestID string
+		err           error
+	)
+	if f.cfg.InstanceConfigurationID == "" {
+		if f.cfg.BootVolumeBackupID != "" {
+			bootVolumeID, err := f.restoredBootVolume(params.AvailabilityDomain)
+			if err != nil {
+				return nil, err
+			}
+			params.BootVolumeID = bootVolumeID
+			f.state.SetPhase(fmt.Sprintf("launching in %s", params.AvailabilityDomain))
+		}
+		instance, workRequestID, err = f.client.CreateInstance(params)
+	} else {
+		instance, workRequestID, err = f.client.LaunchInstanceConfiguration(params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if params.BootVolumeID != "" {
+		// The restored boot volume now belongs to the instance.
+		delete(f.restored, params.AvailabilityDomain)
+	}
+
+	if workRequestID != "" {
+		f.state.SetPhase(fmt.Sprintf("waiting for %s to launch", instance.DisplayName))
+		if _, err := f.client.WaitForWorkRequest(workRequestID); err != nil {
+			var failed *oci.WorkRequestFailedError
+			if errors.As(err, &failed) {
+				return nil, err
+			}
+			// The instance exists; not knowing how its work request ended
+			// is no reason to treat the launch as failed.
+			log.Printf("Warning: could not follow the launch of %s: %v", instance.DisplayName, err)
+		}
+	}
+	return instance, nil
+}
+
+// attemptLaunch makes a single LaunchInstance call and handles its result.
+func (f *finder) attemptLaunch(label string, size config.ShapeSize, params oci.LaunchParams) launchOutcome {
+	ad := params.AvailabilityDomain
+	f.state.SetPhase(fmt.Sprintf("launching in %s", label))
+	instanceDetails, err := f.launch(params)
+	if err != nil {
+		if isTooManyRequests(err) {
+			log.Printf("Checking %s: Too Many Requests.", label)
+			f.state.RecordAttempt(ad, "too many requests")
+			f.state.RecordTooManyRequests()
+			f.backOff(f.current)
+			return launchBackoff
+		}
+		if isOutOfCapacity(err) {
+			log.Printf("Checking %s: Out of capacity.", label)
+			f.state.RecordAttempt(ad, fmt.Sprintf("out of capacity (%s)", size))
+			f.state.RecordOutOfCapacity()
+			f.backoff.Reset() // This wasn't a TMR error.
+			return launchOutOfCapacity
+		}
+		log.Printf("Checking %s: Unrecoverable API Error: %v", label, err)
+		f.state.RecordAttempt(ad, "error")
+		f.state.RecordError()
+		// Treat other API errors like a TMR to pause.
+		f.backOff(f.current)
+		return launchBackoff
+	}
+
+	// --- SUCCESS ---
+	log.Printf("Checking %s: Success! Instance created.", label)
+	f.state.RecordAttempt(ad, fmt.Sprintf("created (%s)", size))
+	f.state.RecordCreated()
+	if size != f.cfg.ShapeSizes()[0] {
+		f.downsized[instanceDetails.ID] = true
+	}
+	prettyDetails, _ := json.MarshalIndent(instanceDetails, "", "  ")
+
+	// Full message for local log
+	logSuccessMessage := fmt.Sprintf("Successfully created instance!\n%s", string(prettyDetails))
+	log.Println(logSuccessMessage)
+
+	// Send notification (JSON, followed by any post-launch results)
+	message := string(prettyDetails)
+	if summary := f.postLaunch(instanceDetails); summary != "" {
+		message += "\n\n" + summary
+	}
+	f.notify(message)
+
+	f.backoff.Reset()
+	return launchCreated
+}
+
+// shapeConfigOf converts a configured size to an OCI shape configuration.
+func shapeConfigOf(size config.ShapeSize) oci.ShapeConfig {
+	return oci.ShapeConfig{Ocpus: float32(size.OCPUs), MemoryInGBs: float32(size.MemoryInGBs)}
+}
+
+// keepRunning reports whether the finder has work left once the target count is reached.
+func (f *finder) keepRunning() bool {
+	return f.cfg.WatchMode || (f.cfg.ResizeIntervalSeconds > 0 && len(f.downsized) > 0)
+}
+
+// resizeDownsized tries to grow instances launched at a fallback size back to
+// the preferred size, at most once per resize interval.
+func (f *finder) resizeDownsized(instances []oci.Instance) {
+	if f.cfg.ResizeIntervalSeconds <= 0 || len(f.downsized) == 0 {
+		return
+	}
+	if time.Since(f.lastResize) < time.Duration(f.cfg.ResizeIntervalSeconds)*time.Second {
+		return
+	}
+	f.lastResize = time.Now()
+
+	preferred := shapeConfigOf(f.cfg.ShapeSizes()[0])
+	byID := make(map[string]oci.Instance, len(instances))
+	for _, instance := range instances {
+		byID[instance.ID] = instance
+	}
+
+	var budget freeBudget
+	if f.cfg.Shape == freeTierShape && !f.cfg.AllowPaid {
+		var err error
+		if budget, err = f.remainingFreeBudget(instances); err != nil {
+			log.Printf("Resizing: failed to compute free-tier budget: %v", err)
+			return
+		}
+	}
+
+	for id := range f.downsized {
+		instance, ok := byID[id]
+		if !ok || !countsTowardTarget(instance, f.cfg) {
+			delete(f.downsized, id)
+			continue
+		}
+		if instance.ShapeConfig != nil && instance.ShapeConfig.Ocpus >= preferred.Ocpus && instance.ShapeConfig.MemoryInGBs >= preferred.MemoryInGBs {
+			delete(f.downsized, id)
+			continue
+		}
+		if instance.LifecycleState != "RUNNING" {
+			continue
+		}
+		if f.cfg.Shape == freeTierShape && !f.cfg.AllowPaid && instance.ShapeConfig != nil {
+			// The instance's current size is already charged against the budget.
+			grown := freeBudget{
+				OCPUs:       budget.OCPUs + instance.ShapeConfig.Ocpus,
+				MemoryInGBs: budget.MemoryInGBs + instance.ShapeConfig.MemoryInGBs,
+			}
+			if !grown.fits(f.cfg.ShapeSizes()[0]) {
+				log.Printf("Resizing %s: the preferred size would exceed the free tier (%s remaining), skipping.", instance.DisplayName, budget)
+				continue
+			}
+		}
+
+		f.state.SetPhase(fmt.Sprintf("resizing %s", instance.DisplayName))
+		client := f.clientFor(id)
+		_, workRequestID, err := client.UpdateInstance(id, preferred)
+		if err == nil && workRequestID != "" {
+			_, err = client.WaitForWorkRequest(workRequestID)
+		}
+		if err != nil {
+			if isTooManyRequests(err) {
+				log.Printf("Resizing %s: Too Many Requests.", instance.DisplayName)
+				f.backOff(f.regionFor(id))
+				return
+			}
+			if isOutOfCapacity(err) {
+				log.Printf("Resizing %s: Out of capacity, will retry later.", instance.DisplayName)
+				continue
+			}
+			log.Printf("Resizing %s: API Error: %v", instance.DisplayName, err)
+			continue
+		}
+
+		delete(f.downsized, id)
+		message := fmt.Sprintf("Resized instance %s (%s) to %s.", instance.DisplayName, id, f.cfg.ShapeSizes()[0])
+		log.Println(message)
+		f.notify(message)
+	}
+}
+
+// notify delivers a message through Telegram, if configured. Messages of a
+// named target are prefixed with its name.
+func (f *finder) notify(message string) {
+	if f.tgNotifier == nil {
+		return
+	}
+	if f.cfg.Name != "" {
+		message = f.cfg.Name + ": " + message
+	}
+	if err := f.tgNotifier.Notify(message); err != nil {
+		log.Printf("Warning: failed to send Telegram notification: %v", err)
+	} else {
+		log.Println("Successfully sent Telegram notification.")
+	}
+}
+
+// isTooManyRequests reports whether err is an OCI rate-limit error.
+func isTooManyRequests(err error) bool {
+	var apiErr *oci.APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == 429 || apiErr.Code == "TooManyRequests")
+}
+
+// isOutOfCapacity reports whether err is an OCI "Out of host capacity" error,
+// returned either by the API call or by a failed work request.
+func isOutOfCapacity(err error) bool {
+	var apiErr *oci.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 500 && strings.Contains(apiErr.Message, "Out of host capacity")
+	}
+	var wrErr *oci.WorkRequestFailedError
+	if errors.As(err, &wrErr) {
+		for _, e := range wrErr.Errors {
+			if strings.Contains(e.Message, "Out of host capacity") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getAvailabilityDomains returns the availability domains to scan, skipping
+// those without service limits available.
+func (f *finder) getAvailabilityDomains() ([]string, error) {
+	adNames, err := configuredAvailabilityDomains(f.client, f.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return f.filterByLimits(adNames), nil
+}
+
+func configuredAvailabilityDomains(client *oci.Client, cfg *config.Config) ([]string, error) {
+	if cfg.AvailabilityDomain != "" {
+		if strings.HasPrefix(cfg.AvailabilityDomain, "[") {
+			var ads []string
+			if err := json.Unmarshal([]byte(cfg.AvailabilityDomain), &ads); err != nil {
+				return nil, fmt.Errorf("failed to parse OCI_AVAILABILITY_DOMAIN as JSON array: %w", err)
+			}
+			return ads, nil
+		}
+		return []string{cfg.AvailabilityDomain}, nil
+	}
+
+	ociAds, err := client.ListAvailabilityDomains()
+	if err != nil {
+		return nil, err
+	}
+	var adNames []string
+	for _, ad := range ociAds {
+		adNames = append(adNames, ad.Name)
+	}
+	return adNames, nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// Oracle reclaims Always Free instances that stay idle for reclaimWindow:
+// 95th-percentile CPU, network and (for A1 shapes) memory utilization all
+// below reclaimThresholdPercent.
+const (
+	reclaimWindow           = 7 * 24 * time.Hour
+	reclaimThresholdPercent = 20
+	metricsNamespace        = "oci_computeagent"
+	// A1 and the AMD/Intel flexible shapes get 1 Gbps of bandwidth per OCPU.
+	bandwidthGbpsPerOCPU = 1
+)
+
+// keepAliveChecker remembers which instances were reported as idle, so that
+// each warning and recovery is sent only once.
+type keepAliveChecker struct {
+	lastCheck time.Time
+	idle      map[string]bool
+}
+
+// utilization is the 95th-percentile utilization of an instance over the
+// reclamation window, in percent. A negative value means no data.
+type utilization struct {
+	CPU, Memory, Network float64
+}
+
+func (u utilization) String() string {
+	format := func(v float64) string {
+		if v < 0 {
+			return "n/a"
+		}
+		return fmt.Sprintf("%.1f%%", v)
+	}
+	return fmt.Sprintf("CPU %s, memory %s, network %s", format(u.CPU), format(u.Memory), format(u.Network))
+}
+
+// below reports whether every measured metric that counts for the shape is
+// below percent.
+func (u utilization) below(percent float64, countsMemory bool) bool {
+	if u.CPU < 0 || u.CPU >= percent || u.Network >= percent {
+		return false
+	}
+	return !countsMemory || u.Memory < percent
+}
+
+// checkKeepAlive warns when counted instances trend toward Oracle's idle
+// reclamation threshold, at most once per OCI_KEEPALIVE_CHECK_HOURS.
+func (f *finder) checkKeepAlive(instances []oci.Instance) {
+	if f.cfg.KeepAliveCheckHours <= 0 {
+		return
+	}
+	if time.Since(f.keepAlive.lastCheck) < time.Duration(f.cfg.KeepAliveCheckHours)*time.Hour {
+		return
+	}
+	f.keepAlive.lastCheck = time.Now()
+	if f.keepAlive.idle == nil {
+		f.keepAlive.idle = make(map[string]bool)
+	}
+
+	for _, instance := range instances {
+		if !countsTowardTarget(instance, f.cfg) || instance.LifecycleState != "RUNNING" {
+			continue
+		}
+
+		f.state.SetPhase(fmt.Sprintf("checking utilization of %s", instance.DisplayName))
+		usage, err := f.instanceUtilization(instance)
+		if err != nil {
+			log.Printf("Keep-alive: could not query metrics for %s: %v", instance.DisplayName, err)
+			continue
+		}
+		if usage.CPU < 0 {
+			log.Printf("Keep-alive: no metrics for %s. Is the Compute Instance Monitoring agent plugin enabled?", instance.DisplayName)
+			continue
+		}
+		log.Printf("Keep-alive: %s 7-day p95 utilization: %s.", instance.DisplayName, usage)
+
+		countsMemory := strings.Contains(instance.Shape, ".A1.")
+		atRisk := usage.below(float64(f.cfg.KeepAliveWarnPercent), countsMemory)
+		switch {
+		case atRisk && !f.keepAlive.idle[instance.ID]:
+			f.keepAlive.idle[instance.ID] = true
+			message := fmt.Sprintf("Instance %s (%s) is at risk of idle reclamation: 7-day p95 %s. Oracle reclaims Always Free instances that stay below %d%%.",
+				instance.DisplayName, instance.ID, usage, reclaimThresholdPercent)
+			if usage.below(reclaimThresholdPercent, countsMemory) {
+				message += " It is already below the threshold."
+			}
+			log.Println(message)
+			f.notify(message)
+		case !atRisk && f.keepAlive.idle[instance.ID]:
+			delete(f.keepAlive.idle, instance.ID)
+			message := fmt.Sprintf("Instance %s (%s) is no longer at risk of idle reclamation: 7-day p95 %s.", instance.DisplayName, instance.ID, usage)
+			log.Println(message)
+			f.notify(message)
+		}
+	}
+}
+
+// instanceUtilization queries the Monitoring API for an instance's
+// 95th-percentile utilization over the reclamation window.
+func (f *finder) instanceUtilization(instance oci.Instance) (utilization, error) {
+	end := time.Now()
+	start := end.Add(-reclaimWindow)
+	query := func(metric, aggregation string) ([]float64, error) {
+		mql := fmt.Sprintf("%s[5m]{resourceId = %q}.%s()", metric, instance.ID, aggregation)
+		metrics, err := f.clientFor(instance.ID).SummarizeMetricsData(instance.CompartmentID, metricsNamespace, mql, start, end)
+		if err != nil {
+			return nil, err
+		}
+		var values []float64
+		for _, m := range metrics {
+			for _, dp := range m.AggregatedDatapoints {
+				values = append(values, dp.Value)
+			}
+		}
+		return values, nil
+	}
+
+	usage := utilization{CPU: -1, Memory: -1, Network: -1}
+	cpu, err := query("CpuUtilization", "mean")
+	if err != nil {
+		return usage, err
+	}
+	usage.CPU = percentile95(cpu)
+
+	memory, err := query("MemoryUtilization", "mean")
+	if err != nil {
+		return usage, err
+	}
+	usage.Memory = percentile95(memory)
+
+	// NetworksBytesIn/Out are byte counts per interval; convert the busier
+	// direction to a share of the shape's bandwidth.
+	var bandwidthBps float64
+	if instance.ShapeConfig != nil {
+		bandwidthBps = float64(instance.ShapeConfig.Ocpus) * bandwidthGbpsPerOCPU * 1e9
+	}
+	for _, metric := range []string{"NetworksBytesIn", "NetworksBytesOut"} {
+		bytes, err := query(metric, "sum")
+		if err != nil {
+			return usage, err
+		}
+		if bandwidthBps <= 0 || len(bytes) == 0 {
+			continue
+		}
+		percent := percentile95(bytes) * 8 / (5 * 60) / bandwidthBps * 100
+		usage.Network = math.Max(usage.Network, percent)
+	}
+	return usage, nil
+}
+
+// percentile95 returns the 95th percentile of values, or -1 if there are none.
+func percentile95(values []float64) float64 {
+	if len(values) == 0 {
+		return -1
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// lifecycleFlags are the flags shared by the instance lifecycle commands.
+type lifecycleFlags struct {
+	*flag.FlagSet
+	tag  *string
+	yes  *bool
+	wait *bool
+}
+
+func newLifecycleFlags(name string) *lifecycleFlags {
+	flags := &lifecycleFlags{FlagSet: flag.NewFlagSet(name, flag.ExitOnError)}
+	flags.tag = flags.String("tag", "", "Also select instances with this freeform key=value tag")
+	flags.yes = flags.Bool("yes", false, "Do not ask for confirmation")
+	flags.wait = flags.Bool("wait", true, "Wait for the work request to finish")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: oahc-go %s [flags] [instance name or OCID...]\n", name)
+		fmt.Fprintln(flags.Output(), "Flags must come before the instances.")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parse parses args and returns the instance references. Parsing stops at the
+// first reference, so a flag after it would be taken as a reference; that is
+// rejected rather than silently ignoring the flag.
+func (flags *lifecycleFlags) parse(args []string) ([]string, error) {
+	flags.Parse(args)
+	for _, ref := range flags.Args() {
+		if strings.HasPrefix(ref, "-") {
+			flags.Usage()
+			return nil, fmt.Errorf("flag %s must come before the instances", ref)
+		}
+	}
+	return flags.Args(), nil
+}
+
+// instanceActionCommand returns a command that performs an instance power action.
+func instanceActionCommand(name, action string) func(*oci.Client, *config.Config, []string) error {
+	return func(client *oci.Client, cfg *config.Config, args []string) error {
+		flags := newLifecycleFlags(name)
+		refs, err := flags.parse(args)
+		if err != nil {
+			return err
+		}
+
+		instances, err := selectInstances(client, refs, *flags.tag)
+		if err != nil {
+			return err
+		}
+		if !*flags.yes && !confirm(fmt.Sprintf("%s %d instance(s)", name, len(instances)), instances) {
+			return fmt.Errorf("aborted")
+		}
+
+		var failed int
+		for _, instance := range instances {
+			_, workRequestID, err := client.InstanceAction(instance.ID, action)
+			if err == nil && *flags.wait && workRequestID != "" {
+				_, err = client.WaitForWorkRequest(workRequestID)
+			}
+			if err != nil {
+				log.Printf("%s %s: %v", action, instance.DisplayName, err)
+				failed++
+				continue
+			}
+			log.Printf("%s %s (%s): done.", action, instance.DisplayName, instance.ID)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d instance(s) failed", failed, len(instances))
+		}
+		return nil
+	}
+}
+
+// runTerminate implements the terminate command.
+func runTerminate(client *oci.Client, cfg *config.Config, args []string) error {
+	flags := newLifecycleFlags("terminate")
+	preserve := flags.Bool("preserve-boot-volume", false, "Keep the boot volume after termination")
+	refs, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+
+	instances, err := selectInstances(client, refs, *flags.tag)
+	if err != nil {
+		return err
+	}
+	what := fmt.Sprintf("TERMINATE %d instance(s) and DELETE their boot volumes", len(instances))
+	if *preserve {
+		what = fmt.Sprintf("TERMINATE %d instance(s), keeping their boot volumes", len(instances))
+	}
+	if !*flags.yes && !confirm(what, instances) {
+		return fmt.Errorf("aborted")
+	}
+
+	var failed int
+	for _, instance := range instances {
+		workRequestID, err := client.TerminateInstance(instance.ID, *preserve)
+		if err == nil && *flags.wait && workRequestID != "" {
+			_, err = client.WaitForWorkRequest(workRequestID)
+		}
+		if err != nil {
+			log.Printf("TERMINATE %s: %v", instance.DisplayName, err)
+			failed++
+			continue
+		}
+		log.Printf("TERMINATE %s (%s): done.", instance.DisplayName, instance.ID)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d instance(s) failed", failed, len(instances))
+	}
+	return nil
+}
+
+// confirm lists the instances and asks on the terminal whether to go ahead.
+// Anything but "y" or "yes", including a closed stdin, is a no.
+func confirm(what string, instances []oci.Instance) bool {
+	fmt.Printf("About to %s:\n", what)
+	for _, instance := range instances {
+		fmt.Printf("  - %s (%s) in %s: %s\n", instance.DisplayName, instance.ID, instance.AvailabilityDomain, instance.LifecycleState)
+	}
+	fmt.Print("Continue? [y/N] ")
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
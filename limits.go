@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// shapeLimit is a per-AD compute service limit, with the amount of it that
+// launching an instance of a given size uses.
+type shapeLimit struct {
+	name   string
+	needed func(size config.ShapeSize) int64
+}
+
+// shapeLimits maps shapes to the per-AD compute service limits that gate launching them.
+var shapeLimits = map[string][]shapeLimit{
+	freeTierShape: {
+		{"standard-a1-core-count", func(size config.ShapeSize) int64 { return int64(size.OCPUs) }},
+		{"standard-a1-memory-count", func(size config.ShapeSize) int64 { return int64(size.MemoryInGBs) }},
+	},
+}
+
+// limitsChecker caches which availability domains have no service limit or quota left.
+type limitsChecker struct {
+	lastCheck    time.Time
+	quotasLogged bool
+	blocked      map[string]string // AD name -> reason
+}
+
+// filterByLimits drops availability domains whose service limits or
+// compartment quotas leave too little to launch the smallest configured size. Limits are re-queried at
+// most once per OCI_LIMITS_CHECK_INTERVAL_SECONDS. Lookup failures never
+// cause a domain to be skipped.
+func (f *finder) filterByLimits(ads []string) []string {
+	limits, ok := shapeLimits[f.cfg.Shape]
+	if !ok || f.cfg.LimitsCheckIntervalSeconds <= 0 {
+		return ads
+	}
+
+	if time.Since(f.limits.lastCheck) >= time.Duration(f.cfg.LimitsCheckIntervalSeconds)*time.Second {
+		f.state.SetPhase("checking service limits")
+		if !f.limits.quotasLogged {
+			f.logQuotaPolicies()
+			f.limits.quotasLogged = true
+		}
+		f.limits.blocked = f.checkLimits(ads, limits)
+		f.limits.lastCheck = time.Now()
+	}
+
+	var available []string
+	for _, ad := range ads {
+		if reason, ok := f.limits.blocked[ad]; ok {
+			f.state.RecordSkipped(ad, reason)
+			continue
+		}
+		available = append(available, ad)
+	}
+	return available
+}
+
+// checkLimits queries each limit in each availability domain and returns the
+// domains that should be skipped, with the reason. Fallback sizes are tried
+// in turn, so a domain is only skipped if no configured size fits its limits.
+func (f *finder) checkLimits(ads []string, limits []shapeLimit) map[string]string {
+	blocked := make(map[string]string)
+	for _, ad := range ads {
+		availabilities := make(map[string]*oci.ResourceAvailability)
+		for _, limit := range limits {
+			availability, err := f.client.GetResourceAvailability(limit.name, f.cfg.LaunchCompartmentID(), ad)
+			if err != nil {
+				log.Printf("Warning: could not check service limit %s in %s: %v. Not skipping it.", limit.name, ad, err)
+				continue
+			}
+			availabilities[limit.name] = availability
+		}
+
+		fits := slices.ContainsFunc(f.cfg.ShapeSizes(), func(size config.ShapeSize) bool {
+			for _, limit := range limits {
+				if availability, ok := availabilities[limit.name]; ok && availability.Available < limit.needed(size) {
+					return false
+				}
+			}
+			return true
+		})
+		if fits {
+			continue
+		}
+
+		var details []string
+		for _, limit := range limits {
+			availability, ok := availabilities[limit.name]
+			if !ok {
+				continue
+			}
+			detail := fmt.Sprintf("%s used %d, available %d", limit.name, availability.Used, availability.Available)
+			if availability.EffectiveQuotaValue != nil {
+				detail += fmt.Sprintf(", compartment quota is %g", *availability.EffectiveQuotaValue)
+			}
+			details = append(details, detail)
+		}
+		reason := fmt.Sprintf("service limits leave too little for any configured size (%s)", strings.Join(details, "; "))
+		log.Printf("Skipping %s: %s.", ad, reason)
+		blocked[ad] = reason
+	}
+	if len(blocked) == 0 {
+		log.Printf("Service limits checked: all %d availability domain(s) have %s capacity available.", len(ads), f.cfg.Shape)
+	}
+	return blocked
+}
+
+// logQuotaPolicies logs quota statements in the tenancy that mention the
+// limits of the configured shape, so a zero limit can be traced to its policy.
+func (f *finder) logQuotaPolicies() {
+	quotas, err := f.client.ListQuotas(f.cfg.TenancyID)
+	if err != nil {
+		log.Printf("Warning: could not list compartment quotas: %v", err)
+		return
+	}
+
+	for _, summary := range quotas {
+		if summary.LifecycleState != "" && summary.LifecycleState != "ACTIVE" {
+			continue
+		}
+		quota, err := f.client.GetQuota(summary.ID)
+		if err != nil {
+			log.Printf("Warning: could not read quota policy %s: %v", summary.Name, err)
+			continue
+		}
+		for _, statement := range quota.Statements {
+			if mentionsLimits(statement, shapeLimits[f.cfg.Shape]) {
+				log.Printf("Quota policy %q affects %s: %s", quota.Name, f.cfg.Shape, statement)
+			}
+		}
+	}
+}
+
+// mentionsLimits reports whether a quota statement names one of the limits,
+// or sets a family-wide compute-core/compute-memory quota.
+func mentionsLimits(statement string, limits []shapeLimit) bool {
+	lower := strings.ToLower(statement)
+	for _, limit := range limits {
+		if strings.Contains(lower, limit.name) {
+			return true
+		}
+	}
+	return (strings.Contains(lower, "compute-core") || strings.Contains(lower, "compute-memory")) &&
+		!strings.Contains(lower, "-count")
+}
@@ -1,149 +1,195 @@
 package main
 
 import (
-	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
-	"github.com/idanyas/oahc-go/backoff"
 	"github.com/idanyas/oahc-go/config"
 	"github.com/idanyas/oahc-go/notifier"
 	"github.com/idanyas/oahc-go/oci"
+	"github.com/idanyas/oahc-go/state"
 )
 
 func main() {
-	envFile := flag.String("envfile", ".env", "Path to the environment file")
-	flag.Parse()
-
-	log.Println("Starting OCI Capacity Finder...")
-
-	cfg, err := config.Load(*envFile)
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+	configFile := flag.String("config", "config.toml", "Path to the TOML config file")
+	envFile := flag.String("envfile", ".env", "Path to the environment file, whose settings override the config file")
+	target := flag.String("target", "", "Target section to run a command against, if the environment file defines several")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: oahc-go [-config config.toml] [-envfile .env] [-target name] [command [args]]")
+		fmt.Fprintln(flag.CommandLine.Output(), "Without a command, runs the capacity finder. Commands: backup, start, stop, softstop, reboot, terminate, schema")
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Invalid configuration: %v", err)
+	if flag.Arg(0) == "schema" {
+		schema, err := config.Schema()
+		if err != nil {
+			log.Fatalf("schema: %v", err)
+		}
+		fmt.Println(string(schema))
+		return
 	}
-
-	signer, err := oci.NewSigner(cfg.TenancyID, cfg.UserID, cfg.KeyFingerprint, cfg.PrivateKeyPath)
-	if err != nil {
-		log.Fatalf("Failed to create OCI signer: %v", err)
+	if flag.NArg() > 0 {
+		if err := runCommand(*configFile, *envFile, *target, flag.Args()); err != nil {
+			log.Fatalf("%s: %v", flag.Arg(0), err)
+		}
+		return
 	}
 
-	client := oci.NewClient(cfg, signer)
-	backoffManager := backoff.NewManager(cfg)
-
-	// Main infinite loop to continuously check for capacity
-	for {
-		instances, err := client.ListInstances()
-		if err != nil {
-			log.Printf("ERROR: Failed to list instances: %v. Retrying in 30s...", err)
-			time.Sleep(30 * time.Second)
-			continue
-		}
-		// A successful API call should reset the backoff state.
-		backoffManager.Reset()
+	log.Println("Starting OCI Capacity Finder...")
 
-		existingInstances := 0
-		for _, instance := range instances {
-			if instance.Shape == cfg.Shape && instance.LifecycleState != "TERMINATED" {
-				existingInstances++
+	// Invalid values and failed checks are reported together.
+	targets, err := config.LoadTargets(*configFile, *envFile)
+	invalid := []error{err}
+	for _, cfg := range targets {
+		if err := cfg.Validate(); err != nil {
+			if cfg.Name != "" {
+				err = config.PrefixErrors("target "+cfg.Name, err)
 			}
+			invalid = append(invalid, err)
 		}
+	}
+	invalid = append(invalid, config.ValidateTargets(targets))
+	if err := errors.Join(invalid...); err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
 
-		if existingInstances >= cfg.MaxInstances {
-			log.Printf("Target instance count (%d) reached. Exiting.", cfg.MaxInstances)
-			return
-		}
-
-		availabilityDomains, err := getAvailabilityDomains(client, cfg)
+	group := state.NewGroup()
+	var finders []*finder
+	var bot *notifier.TelegramBot
+	for _, cfg := range targets {
+		st := state.New(cfg.MaxInstances)
+		f, err := setupTarget(cfg, st)
 		if err != nil {
-			log.Printf("ERROR: Failed to get availability domains: %v. Retrying in 30s...", err)
-			time.Sleep(30 * time.Second)
-			continue
+			if cfg.Name != "" {
+				log.Fatalf("Target %s: %v", cfg.Name, err)
+			}
+			log.Fatal(err)
+		}
+		group.Add(cfg.Name, st)
+		finders = append(finders, f)
+		if cfg.TelegramBotEnabled && bot == nil {
+			bot = notifier.NewTelegramBot(f.tgNotifier, group)
 		}
-		// A successful API call should reset the backoff state.
-		backoffManager.Reset()
+	}
+	if bot != nil {
+		go bot.Run()
+	}
 
-		tmrHitInCycle := false
-		for _, ad := range availabilityDomains {
-			instanceDetails, err := client.CreateInstance(ad)
-			if err != nil {
-				var apiErr *oci.APIError
-				if errors.As(err, &apiErr) {
-					if apiErr.StatusCode == 429 || apiErr.Code == "TooManyRequests" {
-						log.Printf("Checking %s: Too Many Requests.", ad)
-						tmrHitInCycle = true
-						backoffManager.HandleTMR()
-						// Break the inner loop and start a new cycle after the backoff period.
-						break
-					}
-					if apiErr.StatusCode == 500 && strings.Contains(apiErr.Message, "Out of host capacity") {
-						log.Printf("Checking %s: Out of capacity.", ad)
-						backoffManager.Reset() // This wasn't a TMR error.
-						continue
-					}
+	// Stop gracefully on SIGINT or SIGTERM, so that each target cleans up
+	// after itself. A second signal exits immediately.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		signal.Stop(signals)
+		log.Printf("Received %v, stopping.", sig)
+		group.Stop()
+	}()
+
+	if len(finders) == 1 {
+		finders[0].run()
+		return
+	}
+	log.Printf("Running %d targets.", len(finders))
+	var wg sync.WaitGroup
+	for _, f := range finders {
+		wg.Add(1)
+		go func(f *finder) {
+			defer wg.Done()
+			// A panic stops only its own target, not the others.
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Target %s crashed: %v\n%s", f.cfg.Name, r, debug.Stack())
+					f.state.SetPhase("crashed")
+					f.notify(fmt.Sprintf("Crashed: %v", r))
 				}
-				log.Printf("Checking %s: Unrecoverable API Error: %v", ad, err)
-				tmrHitInCycle = true // Treat other API errors like a TMR to pause.
-				backoffManager.HandleTMR()
-				break
-			}
+			}()
+			f.run()
+			log.Printf("Target %s finished.", f.cfg.Name)
+		}(f)
+	}
+	wg.Wait()
+}
 
-			// --- SUCCESS ---
-			log.Printf("Checking %s: Success! Instance created.", ad)
-			prettyDetails, _ := json.MarshalIndent(instanceDetails, "", "  ")
+// setupTarget prepares the finder of a validated target.
+func setupTarget(cfg *config.Config, st *state.State) (*finder, error) {
+	signer, err := oci.NewSigner(cfg.TenancyID, cfg.UserID, cfg.KeyFingerprint, cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCI signer: %w", err)
+	}
 
-			// Full message for local log
-			logSuccessMessage := fmt.Sprintf("Successfully created instance!\n%s", string(prettyDetails))
-			log.Println(logSuccessMessage)
+	client := oci.NewClient(cfg, signer)
+	if err := resolveCompartment(client, cfg); err != nil {
+		return nil, fmt.Errorf("failed to resolve OCI_COMPARTMENT_ID: %w", err)
+	}
+	if err := setupImageResolver(client, cfg); err != nil {
+		return nil, fmt.Errorf("failed to resolve image: %w", err)
+	}
+	if err := preflight(client, cfg); err != nil {
+		return nil, fmt.Errorf("preflight check failed: %w", err)
+	}
+	if len(cfg.Regions) > 0 {
+		if err := checkRegionSubscriptions(client, cfg); err != nil {
+			return nil, fmt.Errorf("invalid OCI_REGIONS: %w", err)
+		}
+	}
 
-			// Send notification (JSON only)
-			if cfg.TelegramBotAPIKey != "" && cfg.TelegramUserID != "" {
-				telegramMessage := string(prettyDetails)
-				tgNotifier := notifier.NewTelegramNotifier(cfg.TelegramBotAPIKey, cfg.TelegramUserID)
-				if err := tgNotifier.Notify(telegramMessage); err != nil {
-					log.Printf("Warning: failed to send Telegram notification: %v", err)
-				} else {
-					log.Println("Successfully sent Telegram notification.")
-				}
-			}
+	var tgNotifier *notifier.TelegramNotifier
+	if cfg.TelegramBotAPIKey != "" && cfg.TelegramUserID != "" {
+		tgNotifier = notifier.NewTelegramNotifier(cfg.TelegramBotAPIKey, cfg.TelegramUserID)
+		tgNotifier.SetBaseURL(cfg.TelegramAPIURL)
+	}
 
-			backoffManager.Reset()
-			return
+	f := newFinder(cfg, client, st, tgNotifier)
+	for _, rc := range cfg.Regions {
+		regional := cfg.ForRegion(rc)
+		regionClient := oci.NewClient(regional, signer)
+		if err := setupImageResolver(regionClient, regional); err != nil {
+			return nil, fmt.Errorf("failed to resolve image in %s: %w", rc.Region, err)
 		}
-
-		// After trying all ADs, reset backoff if no TMR was hit.
-		if !tmrHitInCycle {
-			backoffManager.Reset()
+		if err := preflight(regionClient, regional); err != nil {
+			return nil, fmt.Errorf("preflight check failed in %s: %w", rc.Region, err)
 		}
+		f.addRegion(regional, regionClient)
+	}
+	if len(cfg.Regions) > 0 {
+		log.Printf("Scanning %d regions.", len(cfg.Regions)+1)
 	}
-}
 
-func getAvailabilityDomains(client *oci.Client, cfg *config.Config) ([]string, error) {
-	if cfg.AvailabilityDomain != "" {
-		if strings.HasPrefix(cfg.AvailabilityDomain, "[") {
-			var ads []string
-			if err := json.Unmarshal([]byte(cfg.AvailabilityDomain), &ads); err != nil {
-				return nil, fmt.Errorf("failed to parse OCI_AVAILABILITY_DOMAIN as JSON array: %w", err)
-			}
-			return ads, nil
+	// The Always Free allowance only applies in the home region.
+	if home, err := homeRegionName(client); err != nil {
+		log.Printf("Warning: could not determine the home region: %v. Assuming it is %s.", err, cfg.Region)
+	} else {
+		f.setHome(home, func(regional *config.Config) *oci.Client {
+			return oci.NewClient(regional, signer)
+		})
+		if !strings.EqualFold(home, cfg.Region) {
+			log.Printf("Home region is %s; free-tier usage is checked there.", home)
 		}
-		return []string{cfg.AvailabilityDomain}, nil
 	}
+	return f, nil
+}
 
-	ociAds, err := client.ListAvailabilityDomains()
-	if err != nil {
-		return nil, err
+// setupImageResolver makes the client resolve its image from OCI_IMAGE_OS,
+// checking that an image can be found.
+func setupImageResolver(client *oci.Client, cfg *config.Config) error {
+	if cfg.ImageOS == "" || cfg.BootVolumeID != "" {
+		return nil
 	}
-	var adNames []string
-	for _, ad := range ociAds {
-		adNames = append(adNames, ad.Name)
+	resolver := oci.NewImageResolver(client, cfg.ImageOS, cfg.ImageOSVersion, time.Duration(cfg.ImageRefreshHours)*time.Hour)
+	if _, err := resolver.ImageID(); err != nil {
+		return err
 	}
-	return adNames, nil
-}
\ No newline at end of file
+	client.SetImageResolver(resolver)
+	return nil
+}
@@ -7,30 +7,55 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
+// defaultTelegramAPIURL is the public Telegram Bot API endpoint.
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
 // TelegramNotifier sends messages to a Telegram chat.
 type TelegramNotifier struct {
 	apiKey     string
 	userID     string
+	baseURL    string
 	httpClient *http.Client
 }
 
 // NewTelegramNotifier creates a new notifier for Telegram.
 func NewTelegramNotifier(apiKey, userID string) *TelegramNotifier {
 	return &TelegramNotifier{
-		apiKey: apiKey,
-		userID: userID,
+		apiKey:  apiKey,
+		userID:  userID,
+		baseURL: defaultTelegramAPIURL,
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 	}
 }
 
+// SetBaseURL overrides the Telegram Bot API base URL, e.g. for a self-hosted
+// Bot API server or a test double. An empty value restores the default.
+func (t *TelegramNotifier) SetBaseURL(baseURL string) {
+	if baseURL == "" {
+		baseURL = defaultTelegramAPIURL
+	}
+	t.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// methodURL returns the full URL for a Bot API method.
+func (t *TelegramNotifier) methodURL(method string) string {
+	return fmt.Sprintf("%s/bot%s/%s", t.baseURL, t.apiKey, method)
+}
+
 // Notify sends the given message.
 func (t *TelegramNotifier) Notify(message string) error {
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.apiKey)
+	return t.send(t.userID, message, "Markdown")
+}
+
+// send delivers a message to a chat. An empty parseMode sends plain text.
+func (t *TelegramNotifier) send(chatID, message, parseMode string) error {
+	apiURL := t.methodURL("sendMessage")
 
 	// Telegram messages have a size limit of 4096 characters.
 	if len(message) > 4096 {
@@ -38,9 +63,11 @@ func (t *TelegramNotifier) Notify(message string) error {
 	}
 
 	params := url.Values{}
-	params.Add("chat_id", t.userID)
+	params.Add("chat_id", chatID)
 	params.Add("text", message)
-	params.Add("parse_mode", "Markdown") // Or "HTML"
+	if parseMode != "" {
+		params.Add("parse_mode", parseMode)
+	}
 
 	req, err := http.NewRequest("POST", apiURL, bytes.NewBufferString(params.Encode()))
 	if err != nil {
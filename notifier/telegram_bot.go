@@ -0,0 +1,279 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/idanyas/oahc-go/state"
+)
+
+// pollTimeout is the long-polling timeout passed to getUpdates.
+const pollTimeout = 30 * time.Second
+
+// TelegramBot is a long-polling Telegram bot that lets the configured user
+// inspect and control the finder through chat commands. With several
+// targets, replies cover all of them, and /pause and /resume accept a target
+// name.
+type TelegramBot struct {
+	tg         *TelegramNotifier
+	group      *state.Group
+	httpClient *http.Client
+	offset     int64
+}
+
+// telegramUpdate is the subset of a Bot API Update object used by the bot.
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		From *struct {
+			ID int64 `json:"id"`
+		} `json:"from"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// NewTelegramBot creates a bot that sends replies through tg and reads and
+// controls the states of the targets in group.
+func NewTelegramBot(tg *TelegramNotifier, group *state.Group) *TelegramBot {
+	return &TelegramBot{
+		tg:    tg,
+		group: group,
+		// The client timeout must outlast the long-poll timeout.
+		httpClient: &http.Client{Timeout: pollTimeout + 10*time.Second},
+	}
+}
+
+// Run polls for updates until a stop is requested on the group.
+func (b *TelegramBot) Run() {
+	log.Println("Telegram bot started, listening for commands.")
+	for {
+		select {
+		case <-b.group.Stopped():
+			return
+		default:
+		}
+
+		updates, err := b.getUpdates()
+		if err != nil {
+			log.Printf("Warning: Telegram bot failed to fetch updates: %v", err)
+			if !b.group.Sleep(5 * time.Second) {
+				return
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			b.offset = update.UpdateID + 1
+			b.handleUpdate(update)
+		}
+	}
+}
+
+// getUpdates fetches pending updates using long polling.
+func (b *TelegramBot) getUpdates() ([]telegramUpdate, error) {
+	params := url.Values{}
+	params.Add("offset", strconv.FormatInt(b.offset, 10))
+	params.Add("timeout", strconv.Itoa(int(pollTimeout.Seconds())))
+	params.Add("allowed_updates", `["message"]`)
+
+	resp, err := b.httpClient.Get(b.tg.methodURL("getUpdates") + "?" + params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("telegram API returned non-200 status: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var tgResp struct {
+		Ok     bool             `json:"ok"`
+		Result []telegramUpdate `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tgResp); err != nil {
+		return nil, fmt.Errorf("failed to decode telegram response: %w", err)
+	}
+	if !tgResp.Ok {
+		return nil, fmt.Errorf("telegram API indicated failure")
+	}
+	return tgResp.Result, nil
+}
+
+// handleUpdate dispatches a single update, ignoring anything not sent by the configured user.
+func (b *TelegramBot) handleUpdate(update telegramUpdate) {
+	msg := update.Message
+	if msg == nil || msg.From == nil || !strings.HasPrefix(msg.Text, "/") {
+		return
+	}
+	if strconv.FormatInt(msg.From.ID, 10) != b.tg.userID {
+		log.Printf("Telegram bot: ignoring command from unauthorized user %d.", msg.From.ID)
+		return
+	}
+
+	// Commands may be addressed as "/status@my_bot" in group chats.
+	fields := strings.Fields(msg.Text)
+	command, _, _ := strings.Cut(fields[0], "@")
+
+	reply := b.execute(strings.ToLower(command), fields[1:])
+	if err := b.tg.send(strconv.FormatInt(msg.Chat.ID, 10), reply, ""); err != nil {
+		log.Printf("Warning: Telegram bot failed to send reply: %v", err)
+	}
+}
+
+// execute runs a command and returns the reply text.
+func (b *TelegramBot) execute(command string, args []string) string {
+	switch command {
+	case "/status":
+		return b.perTarget(statusText)
+	case "/pause":
+		return b.pauseOrResume(args, (*state.State).Pause, "Paused",
+			"Paused. No launch attempts will be made until /resume.", "Already paused.")
+	case "/resume":
+		return b.pauseOrResume(args, (*state.State).Resume, "Resumed", "Resumed.", "Not paused.")
+	case "/stats":
+		return b.statsText()
+	case "/ads":
+		return b.perTarget(adsText)
+	case "/instances":
+		return b.perTarget(instancesText)
+	case "/stop":
+		log.Println("Stop requested via Telegram bot.")
+		b.group.Stop()
+		return "Stopping after the current operation."
+	default:
+		return "Commands:\n" +
+			"/status - current state\n" +
+			"/pause [target] - pause launch attempts\n" +
+			"/resume [target] - resume launch attempts\n" +
+			"/stats - attempt counters\n" +
+			"/ads - availability domains and last results\n" +
+			"/instances - instances from the last listing\n" +
+			"/stop - stop the finder"
+	}
+}
+
+// perTarget renders text for every target, headed by the target name when
+// there are several.
+func (b *TelegramBot) perTarget(text func(state.Snapshot) string) string {
+	targets := b.group.Targets()
+	if len(targets) == 1 {
+		return text(targets[0].State.Snapshot())
+	}
+
+	var sb strings.Builder
+	for i, target := range targets {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "[%s]\n%s", target.Name, text(target.State.Snapshot()))
+	}
+	return sb.String()
+}
+
+// pauseOrResume applies action to the named target, or to all targets when
+// no name is given. unchanged is the reply when no target changed.
+func (b *TelegramBot) pauseOrResume(args []string, action func(*state.State) bool, done, doneAll, unchanged string) string {
+	targets := b.group.Targets()
+	if len(args) > 0 {
+		st, ok := b.group.Get(args[0])
+		if !ok {
+			return fmt.Sprintf("Unknown target %q.", args[0])
+		}
+		targets = []state.Target{{Name: args[0], State: st}}
+	}
+
+	changed := 0
+	for _, target := range targets {
+		if action(target.State) {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return unchanged
+	}
+	if len(args) > 0 {
+		log.Printf("%s %s via Telegram bot.", done, args[0])
+		return fmt.Sprintf("%s %s.", done, args[0])
+	}
+	log.Printf("%s via Telegram bot.", done)
+	return doneAll
+}
+
+func statusText(snap state.Snapshot) string {
+	status := "running"
+	if snap.Paused {
+		status = "paused"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Status: %s\n", status)
+	fmt.Fprintf(&sb, "Phase: %s\n", snap.Phase)
+	fmt.Fprintf(&sb, "Uptime: %s\n", time.Since(snap.Started).Round(time.Second))
+	fmt.Fprintf(&sb, "Instances: %d/%d\n", snap.ExistingInstances, snap.MaxInstances)
+	if !snap.LastCycle.IsZero() {
+		fmt.Fprintf(&sb, "Last cycle: %s ago", time.Since(snap.LastCycle).Round(time.Second))
+	} else {
+		sb.WriteString("Last cycle: never")
+	}
+	return sb.String()
+}
+
+func (b *TelegramBot) statsText() string {
+	format := func(stats state.Stats) string {
+		return fmt.Sprintf("Cycles: %d\nLaunch attempts: %d\nOut of capacity: %d\nToo many requests: %d\nErrors: %d\nCreated: %d",
+			stats.Cycles, stats.Attempts, stats.OutOfCapacity, stats.TooManyReqs, stats.Errors, stats.Created)
+	}
+	if len(b.group.Targets()) == 1 {
+		return format(b.group.Totals())
+	}
+	return "[total]\n" + format(b.group.Totals()) + "\n\n" + b.perTarget(func(snap state.Snapshot) string {
+		return format(snap.Stats)
+	})
+}
+
+func adsText(snap state.Snapshot) string {
+	ads := snap.ADs
+	if len(ads) == 0 {
+		return "No availability domains scanned yet."
+	}
+
+	var sb strings.Builder
+	for i, ad := range ads {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if ad.Checked.IsZero() {
+			fmt.Fprintf(&sb, "%s: not checked yet", ad.Name)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s: %s (%s ago)", ad.Name, ad.Result, time.Since(ad.Checked).Round(time.Second))
+	}
+	return sb.String()
+}
+
+func instancesText(snap state.Snapshot) string {
+	instances := snap.Instances
+	if len(instances) == 0 {
+		return "No instances found."
+	}
+
+	var sb strings.Builder
+	for i, instance := range instances {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "%s\n%s, %s\n%s\n%s", instance.DisplayName, instance.Shape, instance.LifecycleState, instance.AvailabilityDomain, instance.ID)
+	}
+	return sb.String()
+}
@@ -0,0 +1,129 @@
+package notifier
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/idanyas/oahc-go/state"
+)
+
+// fakeBotAPI is a Bot API server that serves updates once and records the
+// messages sent in reply.
+type fakeBotAPI struct {
+	mu      sync.Mutex
+	updates []map[string]any
+	sent    []map[string]string
+}
+
+func (api *fakeBotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+		updates := api.updates
+		api.updates = nil
+		json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": updates})
+	case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+		r.ParseForm()
+		api.sent = append(api.sent, map[string]string{"chat_id": r.Form.Get("chat_id"), "text": r.Form.Get("text")})
+		json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// message returns an update carrying a command sent by user in chat 99.
+func message(id int64, user int64, text string) map[string]any {
+	return map[string]any{
+		"update_id": id,
+		"message": map[string]any{
+			"text": text,
+			"from": map[string]any{"id": user},
+			"chat": map[string]any{"id": 99},
+		},
+	}
+}
+
+func TestTelegramBotCommands(t *testing.T) {
+	api := &fakeBotAPI{updates: []map[string]any{
+		message(1, 42, "/status"),
+		message(2, 7, "/pause"),
+		message(3, 42, "/pause@my_bot"),
+		message(4, 42, "/stop"),
+	}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	tg := NewTelegramNotifier("key", "42")
+	tg.SetBaseURL(server.URL + "/")
+	st := state.New(2)
+	st.SetPhase("listing instances")
+	group := state.NewGroup()
+	group.Add("", st)
+
+	done := make(chan struct{})
+	go func() {
+		NewTelegramBot(tg, group).Run()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("bot did not stop after /stop")
+	}
+
+	if !st.Paused() {
+		t.Error("/pause did not pause the target")
+	}
+	select {
+	case <-st.Stopped():
+	default:
+		t.Error("/stop did not stop the target")
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	// The command from user 7 is ignored.
+	if len(api.sent) != 3 {
+		t.Fatalf("sent %d replies, want 3: %v", len(api.sent), api.sent)
+	}
+	for i, want := range []string{"Phase: listing instances", "Paused.", "Stopping"} {
+		if reply := api.sent[i]; reply["chat_id"] != "99" || !strings.Contains(reply["text"], want) {
+			t.Errorf("reply %d = %v, want %q in chat 99", i, reply, want)
+		}
+	}
+}
+
+func TestTelegramBotPauseTarget(t *testing.T) {
+	personal, work := state.New(1), state.New(1)
+	group := state.NewGroup()
+	group.Add("personal", personal)
+	group.Add("work", work)
+	bot := NewTelegramBot(NewTelegramNotifier("key", "42"), group)
+
+	if reply := bot.execute("/pause", []string{"work"}); reply != "Paused work." {
+		t.Errorf("/pause work = %q", reply)
+	}
+	if personal.Paused() || !work.Paused() {
+		t.Errorf("paused personal=%v work=%v, want only work", personal.Paused(), work.Paused())
+	}
+	if reply := bot.execute("/pause", []string{"home"}); reply != `Unknown target "home".` {
+		t.Errorf("/pause home = %q", reply)
+	}
+	if reply := bot.execute("/resume", nil); reply != "Resumed." {
+		t.Errorf("/resume = %q", reply)
+	}
+	if work.Paused() {
+		t.Error("/resume did not resume work")
+	}
+
+	status := bot.execute("/status", nil)
+	if !strings.Contains(status, "[personal]") || !strings.Contains(status, "[work]") {
+		t.Errorf("/status does not cover both targets:\n%s", status)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -27,6 +29,11 @@ type Client struct {
 	httpClient      *http.Client
 	lastRequestTime time.Time
 	pacerMutex      sync.Mutex
+	imageResolver   *ImageResolver
+
+	// instanceConfigurationTags caches the freeform tags of the instance
+	// configuration merged with the count tag.
+	instanceConfigurationTags map[string]string
 }
 
 // NewClient creates a new OCI API client.
@@ -40,6 +47,12 @@ func NewClient(cfg *config.Config, signer *Signer) *Client {
 	}
 }
 
+// SetImageResolver makes CreateInstance launch from the image chosen by r
+// instead of the configured image OCID.
+func (c *Client) SetImageResolver(r *ImageResolver) {
+	c.imageResolver = r
+}
+
 // paceRequest ensures that requests are spaced out to avoid hitting rate limits.
 // It enforces a maximum of ~3 requests per minute.
 func (c *Client) paceRequest() {
@@ -69,18 +82,68 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("OCI API Error (status %d): %s - %s", e.StatusCode, e.Code, e.Message)
 }
 
-func (c *Client) buildAndDo(method, path string, queryParams url.Values, body interface{}) ([]byte, error) {
-	// Proactively wait to ensure we comply with rate limits before making the call.
-	c.paceRequest()
+// OCI services the client talks to.
+const (
+	serviceCompute    = "iaas"
+	serviceIdentity   = "identity"
+	serviceLimits     = "limits"
+	serviceQuotas     = "quotas"
+	serviceMonitoring = "telemetry"
+)
+
+// endpoint returns the versioned base URL of an OCI service in the configured region.
+func (c *Client) endpoint(service string) string {
+	switch service {
+	case serviceIdentity:
+		return fmt.Sprintf("https://identity.%s.oraclecloud.com/20160918", c.cfg.Region)
+	case serviceLimits:
+		return fmt.Sprintf("https://limits.%s.oci.oraclecloud.com/20190729", c.cfg.Region)
+	case serviceQuotas:
+		return fmt.Sprintf("https://limits.%s.oci.oraclecloud.com/20181025", c.cfg.Region)
+	case serviceMonitoring:
+		return fmt.Sprintf("https://telemetry.%s.oraclecloud.com/20180401", c.cfg.Region)
+	default:
+		return fmt.Sprintf("https://iaas.%s.oraclecloud.com/20160918", c.cfg.Region)
+	}
+}
+
+func (c *Client) buildAndDo(service, method, path string, queryParams url.Values, body interface{}) ([]byte, error) {
+	respBody, _, err := c.buildAndDoWithHeaders(service, method, path, queryParams, body)
+	return respBody, err
+}
+
+// listAll calls a list operation and follows opc-next-page until every page
+// has been fetched.
+func listAll[T any](c *Client, service, path string, queryParams url.Values) ([]T, error) {
+	var items []T
+	for {
+		respBody, headers, err := c.buildAndDoWithHeaders(service, http.MethodGet, path, queryParams, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []T
+		if err := json.Unmarshal(respBody, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
 
-	baseURL := fmt.Sprintf("https://iaas.%s.oraclecloud.com/20160918", c.cfg.Region)
-	if path == "/availabilityDomains/" {
-		baseURL = fmt.Sprintf("https://identity.%s.oraclecloud.com/20160918", c.cfg.Region)
+		next := headers.Get("opc-next-page")
+		if next == "" {
+			return items, nil
+		}
+		queryParams.Set("page", next)
 	}
+}
+
+// buildAndDoWithHeaders is buildAndDo for calls whose response headers are
+// needed, such as the opc-work-request-id of asynchronous operations.
+func (c *Client) buildAndDoWithHeaders(service, method, path string, queryParams url.Values, body interface{}) ([]byte, http.Header, error) {
+	// Proactively wait to ensure we comply with rate limits before making the call.
+	c.paceRequest()
 
-	fullURL, err := url.Parse(baseURL + path)
+	fullURL, err := url.Parse(c.endpoint(service) + path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse URL: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
 	if queryParams != nil {
 		fullURL.RawQuery = queryParams.Encode()
@@ -90,37 +153,39 @@ func (c *Client) buildAndDo(method, path string, queryParams url.Values, body in
 	if body != nil {
 		reqBody, err = json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 	}
 
 	req, err := http.NewRequest(method, fullURL.String(), bytes.NewReader(reqBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	if err := c.signer.Sign(req, reqBody); err != nil {
-		return nil, fmt.Errorf("failed to sign request: %w", err)
+		return nil, nil, fmt.Errorf("failed to sign request: %w", err)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	// If configured, log specific API responses to a file.
-	// We log all instance creation attempts, and all other failed API calls.
+	// We log all instance creation attempts (including instance configuration launches) and capacity reports, and all other failed API calls.
 	if c.cfg.JSONLogPath != "" {
-		isCreateInstance := method == http.MethodPost && path == "/instances/"
+		isCreateInstance := service == serviceCompute && method == http.MethodPost &&
+			(path == "/instances/" || strings.HasSuffix(path, "/actions/launch"))
+		isCapacityReport := service == serviceCompute && method == http.MethodPost && path == "/computeCapacityReports"
 		isFailedResponse := resp.StatusCode < 200 || resp.StatusCode >= 300
 
-		if isCreateInstance || isFailedResponse {
+		if isCreateInstance || isCapacityReport || isFailedResponse {
 			go logResponseToFile(c.cfg.JSONLogPath, resp.Request.Method, resp.Request.URL.String(), resp.StatusCode, respBody)
 		}
 	}
@@ -129,14 +194,14 @@ func (c *Client) buildAndDo(method, path string, queryParams url.Values, body in
 		apiErr := &APIError{StatusCode: resp.StatusCode}
 		// Try to unmarshal into the structured error format
 		if json.Unmarshal(respBody, apiErr) == nil {
-			return nil, apiErr
+			return nil, nil, apiErr
 		}
 		// If unmarshal fails, return a generic error
 		apiErr.Message = string(respBody)
-		return nil, apiErr
+		return nil, nil, apiErr
 	}
 
-	return respBody, nil
+	return respBody, resp.Header, nil
 }
 
 // logResponseToFile appends the details of an API response to the specified log file.
@@ -176,21 +241,35 @@ func logResponseToFile(path, method, url string, statusCode int, body []byte) {
 	}
 }
 
-// ListInstances fetches the list of compute instances.
+// ListInstances fetches the list of compute instances in the configured compartment.
 func (c *Client) ListInstances() ([]Instance, error) {
+	return c.ListInstancesInCompartment(c.cfg.LaunchCompartmentID())
+}
+
+// ListInstancesInCompartment fetches all compute instances in a compartment.
+func (c *Client) ListInstancesInCompartment(compartmentID string) ([]Instance, error) {
 	params := url.Values{}
-	params.Add("compartmentId", c.cfg.TenancyID)
+	params.Add("compartmentId", compartmentID)
 
-	respBody, err := c.buildAndDo(http.MethodGet, "/instances/", params, nil)
+	instances, err := listAll[Instance](c, serviceCompute, "/instances/", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+	return instances, nil
+}
+
+// GetInstance fetches a single compute instance by OCID.
+func (c *Client) GetInstance(instanceID string) (*Instance, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/instances/"+instanceID, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var instances []Instance
-	if err := json.Unmarshal(respBody, &instances); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal instances response: %w", err)
+	var instance Instance
+	if err := json.Unmarshal(respBody, &instance); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal instance response: %w", err)
 	}
-	return instances, nil
+	return &instance, nil
 }
 
 // ListAvailabilityDomains fetches the list of availability domains.
@@ -198,7 +277,7 @@ func (c *Client) ListAvailabilityDomains() ([]AvailabilityDomain, error) {
 	params := url.Values{}
 	params.Add("compartmentId", c.cfg.TenancyID)
 
-	respBody, err := c.buildAndDo(http.MethodGet, "/availabilityDomains/", params, nil)
+	respBody, err := c.buildAndDo(serviceIdentity, http.MethodGet, "/availabilityDomains/", params, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -210,52 +289,228 @@ func (c *Client) ListAvailabilityDomains() ([]AvailabilityDomain, error) {
 	return domains, nil
 }
 
-// CreateInstance attempts to launch a new compute instance.
-func (c *Client) CreateInstance(availabilityDomain string) (*Instance, error) {
+// CreateInstance attempts to launch a new compute instance. It returns the
+// instance and the OCID of the work request tracking the launch.
+func (c *Client) CreateInstance(params LaunchParams) (*Instance, string, error) {
 	// Build SourceDetails based on config
 	var sourceDetails map[string]interface{}
-	if c.cfg.BootVolumeID != "" {
+	bootVolumeID := c.cfg.BootVolumeID
+	if params.BootVolumeID != "" {
+		bootVolumeID = params.BootVolumeID
+	}
+	if bootVolumeID != "" {
 		sourceDetails = map[string]interface{}{
 			"sourceType":   "bootVolume",
-			"bootVolumeId": c.cfg.BootVolumeID,
+			"bootVolumeId": bootVolumeID,
 		}
 	} else {
+		imageID := c.cfg.ImageID
+		if c.imageResolver != nil {
+			var err error
+			if imageID, err = c.imageResolver.ImageID(); err != nil {
+				return nil, "", fmt.Errorf("failed to resolve image: %w", err)
+			}
+		}
 		sourceDetails = map[string]interface{}{
 			"sourceType": "image",
-			"imageId":    c.cfg.ImageID,
+			"imageId":    imageID,
 		}
 		if c.cfg.BootVolumeSizeGbs > 0 {
 			sourceDetails["bootVolumeSizeInGBs"] = c.cfg.BootVolumeSizeGbs
 		}
 	}
 
+	now := time.Now()
+	displayName := fmt.Sprintf("instance-%s", now.Format("20060102-1504"))
+	hostname, _ := os.Hostname()
+	freeformTags, definedTags, err := c.cfg.InstanceTags(config.TagVars{
+		LaunchTime:         now.UTC().Format(time.RFC3339),
+		AvailabilityDomain: params.AvailabilityDomain,
+		DisplayName:        displayName,
+		Shape:              c.cfg.Shape,
+		Hostname:           hostname,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to render tags: %w", err)
+	}
+
 	reqBody := CreateInstanceDetails{
-		AvailabilityDomain: availabilityDomain,
-		CompartmentID:      c.cfg.TenancyID,
+		AvailabilityDomain: params.AvailabilityDomain,
+		CompartmentID:      c.cfg.LaunchCompartmentID(),
 		Shape:              c.cfg.Shape,
-		DisplayName:        fmt.Sprintf("instance-%s", time.Now().Format("20060102-1504")),
-		Metadata:           map[string]string{"ssh_authorized_keys": c.cfg.SSHKey},
+		DisplayName:        displayName,
+		Metadata:           c.cfg.InstanceMetadata(),
+		ExtendedMetadata:   c.cfg.ExtendedMetadata,
 		SourceDetails:      sourceDetails,
 		CreateVnicDetails: &VnicDetails{
 			SubnetID:               c.cfg.SubnetID,
-			AssignPublicIP:         false,
+			AssignPublicIP:         c.cfg.AssignPublicIP,
 			AssignPrivateDNSRecord: true,
+			AssignIpv6IP:           c.cfg.AssignIpv6,
+			DisplayName:            c.cfg.VnicDisplayName,
+			HostnameLabel:          c.cfg.HostnameLabel,
+			NsgIDs:                 c.cfg.NsgIDs,
+			PrivateIP:              c.cfg.PrivateIP,
+			SkipSourceDestCheck:    c.cfg.SkipSourceDestCheck,
 		},
-		ShapeConfig: &ShapeConfig{
-			Ocpus:       c.cfg.OCPUs,
-			MemoryInGBs: c.cfg.MemoryInGBs,
-		},
+		ShapeConfig:           &params.ShapeConfig,
+		CapacityReservationID: params.CapacityReservationID,
+		FreeformTags:          freeformTags,
+		DefinedTags:           definedTags,
+		FaultDomain:           params.FaultDomain,
+		LaunchOptions:         c.launchOptions(),
+		PlatformConfig:        c.platformConfig(),
+		AvailabilityConfig:    c.availabilityConfig(),
+		AgentConfig:           c.agentConfig(),
+	}
+	if c.cfg.LegacyIMDSDisabled {
+		reqBody.InstanceOptions = &InstanceOptions{AreLegacyImdsEndpointsDisabled: true}
+	}
+
+	respBody, header, err := c.buildAndDoWithHeaders(serviceCompute, http.MethodPost, "/instances/", nil, reqBody)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var instance Instance
+	if err := json.Unmarshal(respBody, &instance); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal create instance response: %w", err)
 	}
 
-	respBody, err := c.buildAndDo(http.MethodPost, "/instances/", nil, reqBody)
+	return &instance, header.Get("opc-work-request-id"), nil
+}
+
+// launchOptions returns the configured launch options, or nil to use the image defaults.
+func (c *Client) launchOptions() *LaunchOptions {
+	if c.cfg.LaunchFirmware == "" && c.cfg.LaunchNetworkType == "" && c.cfg.LaunchBootVolumeType == "" && !c.cfg.PvEncryptionInTransit {
+		return nil
+	}
+	return &LaunchOptions{
+		Firmware:                       c.cfg.LaunchFirmware,
+		NetworkType:                    c.cfg.LaunchNetworkType,
+		BootVolumeType:                 c.cfg.LaunchBootVolumeType,
+		IsPvEncryptionInTransitEnabled: c.cfg.PvEncryptionInTransit,
+	}
+}
+
+// platformConfig returns the Shielded Instance settings, or nil if none are configured.
+func (c *Client) platformConfig() *PlatformConfig {
+	if !c.cfg.HasPlatformConfig() {
+		return nil
+	}
+	return &PlatformConfig{
+		Type:                           c.cfg.PlatformType,
+		IsSecureBootEnabled:            c.cfg.SecureBoot,
+		IsMeasuredBootEnabled:          c.cfg.MeasuredBoot,
+		IsTrustedPlatformModuleEnabled: c.cfg.TrustedPlatformModule,
+	}
+}
+
+// availabilityConfig returns the maintenance recovery settings, or nil if none are configured.
+func (c *Client) availabilityConfig() *AvailabilityConfig {
+	if c.cfg.RecoveryAction == "" && c.cfg.LiveMigrationPreferred == nil {
+		return nil
+	}
+	return &AvailabilityConfig{
+		RecoveryAction:           c.cfg.RecoveryAction,
+		IsLiveMigrationPreferred: c.cfg.LiveMigrationPreferred,
+	}
+}
+
+// agentConfig returns the Oracle Cloud Agent settings, or nil to use the image defaults.
+func (c *Client) agentConfig() *AgentConfig {
+	if !c.cfg.HasAgentConfig() {
+		return nil
+	}
+	agentConfig := &AgentConfig{
+		IsMonitoringDisabled:  c.cfg.AgentMonitoringDisabled,
+		IsManagementDisabled:  c.cfg.AgentManagementDisabled,
+		AreAllPluginsDisabled: c.cfg.AgentAllPluginsDisabled,
+	}
+	for _, plugin := range c.cfg.AgentPlugins {
+		agentConfig.PluginsConfig = append(agentConfig.PluginsConfig, AgentPluginConfig{
+			Name:         plugin.Name,
+			DesiredState: plugin.DesiredState,
+		})
+	}
+	return agentConfig
+}
+
+// UpdateInstance resizes an existing flexible-shape instance. OCI reboots the
+// instance to apply the new shape configuration. It returns the OCID of the
+// work request tracking the resize.
+func (c *Client) UpdateInstance(instanceID string, shapeConfig ShapeConfig) (*Instance, string, error) {
+	reqBody := UpdateInstanceDetails{ShapeConfig: &shapeConfig}
+
+	respBody, header, err := c.buildAndDoWithHeaders(serviceCompute, http.MethodPut, "/instances/"+instanceID, nil, reqBody)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var instance Instance
+	if err := json.Unmarshal(respBody, &instance); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal update instance response: %w", err)
+	}
+
+	return &instance, header.Get("opc-work-request-id"), nil
+}
+
+// CreateComputeCapacityReport asks OCI how many instances of each shape
+// configuration could currently be launched in an availability domain.
+// Entries without an InstanceShape use the configured shape.
+func (c *Client) CreateComputeCapacityReport(availabilityDomain string, shapeAvailabilities []CreateCapacityReportShapeAvailabilityDetails) (*ComputeCapacityReport, error) {
+	reqBody := CreateComputeCapacityReportDetails{
+		CompartmentID:       c.cfg.LaunchCompartmentID(),
+		AvailabilityDomain:  availabilityDomain,
+		ShapeAvailabilities: shapeAvailabilities,
+	}
+	for i := range reqBody.ShapeAvailabilities {
+		if reqBody.ShapeAvailabilities[i].InstanceShape == "" {
+			reqBody.ShapeAvailabilities[i].InstanceShape = c.cfg.Shape
+		}
+	}
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodPost, "/computeCapacityReports", nil, reqBody)
 	if err != nil {
 		return nil, err
 	}
 
+	var report ComputeCapacityReport
+	if err := json.Unmarshal(respBody, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal capacity report response: %w", err)
+	}
+	return &report, nil
+}
+
+// InstanceAction performs a power action (START, STOP, SOFTSTOP, SOFTRESET,
+// RESET) on an instance. It returns the instance and the OCID of the work
+// request tracking the action.
+func (c *Client) InstanceAction(instanceID, action string) (*Instance, string, error) {
+	params := url.Values{}
+	params.Add("action", action)
+
+	respBody, header, err := c.buildAndDoWithHeaders(serviceCompute, http.MethodPost, "/instances/"+instanceID, params, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
 	var instance Instance
 	if err := json.Unmarshal(respBody, &instance); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal create instance response: %w", err)
+		return nil, "", fmt.Errorf("failed to unmarshal instance action response: %w", err)
 	}
+	return &instance, header.Get("opc-work-request-id"), nil
+}
 
-	return &instance, nil
+// TerminateInstance terminates an instance, deleting its boot volume unless
+// preserveBootVolume is set. It returns the OCID of the work request tracking
+// the termination.
+func (c *Client) TerminateInstance(instanceID string, preserveBootVolume bool) (string, error) {
+	params := url.Values{}
+	params.Add("preserveBootVolume", strconv.FormatBool(preserveBootVolume))
+
+	_, header, err := c.buildAndDoWithHeaders(serviceCompute, http.MethodDelete, "/instances/"+instanceID, params, nil)
+	if err != nil {
+		return "", err
+	}
+	return header.Get("opc-work-request-id"), nil
 }
@@ -0,0 +1,118 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ListBootVolumeAttachments fetches the boot volume attachments of an instance.
+func (c *Client) ListBootVolumeAttachments(availabilityDomain, compartmentID, instanceID string) ([]BootVolumeAttachment, error) {
+	params := url.Values{}
+	params.Add("availabilityDomain", availabilityDomain)
+	params.Add("compartmentId", compartmentID)
+	params.Add("instanceId", instanceID)
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/bootVolumeAttachments/", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []BootVolumeAttachment
+	if err := json.Unmarshal(respBody, &attachments); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal boot volume attachments response: %w", err)
+	}
+	return attachments, nil
+}
+
+// ListBootVolumes fetches the boot volumes in a compartment, in every
+// availability domain.
+func (c *Client) ListBootVolumes(compartmentID string) ([]BootVolume, error) {
+	params := url.Values{}
+	params.Add("compartmentId", compartmentID)
+
+	bootVolumes, err := listAll[BootVolume](c, serviceCompute, "/bootVolumes", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list boot volumes: %w", err)
+	}
+	return bootVolumes, nil
+}
+
+// CreateBootVolumeFromBackup restores a boot volume backup into an
+// availability domain. A sizeInGBs of 0 keeps the backup's size.
+func (c *Client) CreateBootVolumeFromBackup(availabilityDomain, backupID, displayName string, sizeInGBs int) (*BootVolume, error) {
+	reqBody := CreateBootVolumeDetails{
+		AvailabilityDomain: availabilityDomain,
+		CompartmentID:      c.cfg.LaunchCompartmentID(),
+		DisplayName:        displayName,
+		SizeInGBs:          int64(sizeInGBs),
+		SourceDetails:      BootVolumeSourceDetails{Type: "bootVolumeBackup", ID: backupID},
+	}
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodPost, "/bootVolumes", nil, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var bootVolume BootVolume
+	if err := json.Unmarshal(respBody, &bootVolume); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal create boot volume response: %w", err)
+	}
+	return &bootVolume, nil
+}
+
+// GetBootVolume fetches a boot volume by OCID.
+func (c *Client) GetBootVolume(bootVolumeID string) (*BootVolume, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/bootVolumes/"+bootVolumeID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var bootVolume BootVolume
+	if err := json.Unmarshal(respBody, &bootVolume); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal boot volume response: %w", err)
+	}
+	return &bootVolume, nil
+}
+
+// DeleteBootVolume deletes an unattached boot volume.
+func (c *Client) DeleteBootVolume(bootVolumeID string) error {
+	_, err := c.buildAndDo(serviceCompute, http.MethodDelete, "/bootVolumes/"+bootVolumeID, nil, nil)
+	return err
+}
+
+// CreateBootVolumeBackup starts a backup of a boot volume. backupType is
+// FULL or INCREMENTAL; empty lets OCI choose.
+func (c *Client) CreateBootVolumeBackup(bootVolumeID, displayName, backupType string) (*BootVolumeBackup, error) {
+	reqBody := CreateBootVolumeBackupDetails{
+		BootVolumeID: bootVolumeID,
+		DisplayName:  displayName,
+		Type:         backupType,
+	}
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodPost, "/bootVolumeBackups", nil, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var backup BootVolumeBackup
+	if err := json.Unmarshal(respBody, &backup); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal create boot volume backup response: %w", err)
+	}
+	return &backup, nil
+}
+
+// GetBootVolumeBackup fetches a boot volume backup by OCID.
+func (c *Client) GetBootVolumeBackup(backupID string) (*BootVolumeBackup, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/bootVolumeBackups/"+backupID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var backup BootVolumeBackup
+	if err := json.Unmarshal(respBody, &backup); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal boot volume backup response: %w", err)
+	}
+	return &backup, nil
+}
@@ -0,0 +1,55 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ListCompartments fetches the active child compartments of a compartment,
+// optionally filtered by exact name.
+func (c *Client) ListCompartments(parentID, name string) ([]Compartment, error) {
+	params := url.Values{}
+	params.Add("compartmentId", parentID)
+	params.Add("lifecycleState", "ACTIVE")
+	if name != "" {
+		params.Add("name", name)
+	}
+
+	compartments, err := listAll[Compartment](c, serviceIdentity, "/compartments", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list compartments: %w", err)
+	}
+	return compartments, nil
+}
+
+// ListTenancyCompartments fetches every active compartment in the tenancy,
+// at any depth. The root compartment (the tenancy itself) is not included.
+func (c *Client) ListTenancyCompartments() ([]Compartment, error) {
+	params := url.Values{}
+	params.Add("compartmentId", c.cfg.TenancyID)
+	params.Add("compartmentIdInSubtree", "true")
+	params.Add("accessLevel", "ANY")
+	params.Add("lifecycleState", "ACTIVE")
+
+	compartments, err := listAll[Compartment](c, serviceIdentity, "/compartments", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list compartments: %w", err)
+	}
+	return compartments, nil
+}
+
+// ListRegionSubscriptions fetches the regions the tenancy is subscribed to.
+func (c *Client) ListRegionSubscriptions() ([]RegionSubscription, error) {
+	respBody, err := c.buildAndDo(serviceIdentity, http.MethodGet, "/tenancies/"+c.cfg.TenancyID+"/regionSubscriptions", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var subscriptions []RegionSubscription
+	if err := json.Unmarshal(respBody, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal region subscriptions response: %w", err)
+	}
+	return subscriptions, nil
+}
@@ -0,0 +1,113 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ListImages fetches available images for an operating system and version
+// that are compatible with shape, newest first.
+func (c *Client) ListImages(operatingSystem, operatingSystemVersion, shape string) ([]Image, error) {
+	params := url.Values{}
+	params.Add("compartmentId", c.cfg.TenancyID)
+	params.Add("operatingSystem", operatingSystem)
+	if operatingSystemVersion != "" {
+		params.Add("operatingSystemVersion", operatingSystemVersion)
+	}
+	params.Add("shape", shape)
+	params.Add("lifecycleState", "AVAILABLE")
+	params.Add("sortBy", "TIMECREATED")
+	params.Add("sortOrder", "DESC")
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/images", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var images []Image
+	if err := json.Unmarshal(respBody, &images); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal images response: %w", err)
+	}
+	return images, nil
+}
+
+// ImageResolver picks the newest image for an operating system and version
+// that suits the configured shape, caching the choice for a refresh interval.
+type ImageResolver struct {
+	client          *Client
+	operatingSystem string
+	version         string
+	refresh         time.Duration
+
+	mu         sync.Mutex
+	imageID    string
+	resolvedAt time.Time
+}
+
+// NewImageResolver creates a resolver. The first call to ImageID queries OCI.
+func NewImageResolver(client *Client, operatingSystem, version string, refresh time.Duration) *ImageResolver {
+	return &ImageResolver{
+		client:          client,
+		operatingSystem: operatingSystem,
+		version:         version,
+		refresh:         refresh,
+	}
+}
+
+// ImageID returns the resolved image OCID, re-querying OCI once the cached
+// choice is older than the refresh interval. If a refresh fails, the
+// previous choice is kept.
+func (r *ImageResolver) ImageID() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.imageID != "" && time.Since(r.resolvedAt) < r.refresh {
+		return r.imageID, nil
+	}
+
+	image, err := r.resolve()
+	if err != nil {
+		if r.imageID != "" {
+			log.Printf("Warning: failed to refresh image for %s %s, keeping %s: %v", r.operatingSystem, r.version, r.imageID, err)
+			r.resolvedAt = time.Now()
+			return r.imageID, nil
+		}
+		return "", err
+	}
+
+	if image.ID != r.imageID {
+		log.Printf("Resolved image for %s %s: %s (%s).", r.operatingSystem, r.version, image.DisplayName, image.ID)
+	}
+	r.imageID = image.ID
+	r.resolvedAt = time.Now()
+	return r.imageID, nil
+}
+
+// resolve lists matching images and returns the newest one of the right architecture.
+func (r *ImageResolver) resolve() (*Image, error) {
+	shape := r.client.cfg.Shape
+	images, err := r.client.ListImages(r.operatingSystem, r.version, shape)
+	if err != nil {
+		return nil, err
+	}
+
+	// Results are sorted newest first.
+	arm := isArmShape(shape)
+	for i := range images {
+		if strings.Contains(strings.ToLower(images[i].DisplayName), "aarch64") == arm {
+			return &images[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no image found for %s %s compatible with %s", r.operatingSystem, r.version, shape)
+}
+
+// isArmShape reports whether shape runs on Ampere (aarch64) processors.
+func isArmShape(shape string) bool {
+	return strings.Contains(shape, ".A1.") || strings.Contains(shape, ".A2.")
+}
@@ -0,0 +1,84 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+)
+
+// GetInstanceConfiguration fetches an instance configuration.
+func (c *Client) GetInstanceConfiguration(instanceConfigurationID string) (*InstanceConfiguration, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/instanceConfigurations/"+instanceConfigurationID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var configuration InstanceConfiguration
+	if err := json.Unmarshal(respBody, &configuration); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal instance configuration response: %w", err)
+	}
+	return &configuration, nil
+}
+
+// LaunchInstanceConfiguration launches an instance from the configured
+// instance configuration, overriding only the availability domain, fault
+// domain, subnet, shape config and capacity reservation. With OCI_COUNT_TAG,
+// the count tag is added to the configuration's freeform tags so that the
+// instance is counted. It returns the new instance and the OCID of the work
+// request tracking the launch.
+func (c *Client) LaunchInstanceConfiguration(params LaunchParams) (*Instance, string, error) {
+	reqBody := ComputeInstanceDetails{
+		InstanceType: "compute",
+		LaunchDetails: &InstanceConfigurationLaunchDetails{
+			AvailabilityDomain:    params.AvailabilityDomain,
+			FaultDomain:           params.FaultDomain,
+			ShapeConfig:           &params.ShapeConfig,
+			CapacityReservationID: params.CapacityReservationID,
+			CreateVnicDetails:     &InstanceConfigurationCreateVnicDetails{SubnetID: c.cfg.SubnetID},
+		},
+	}
+	if c.cfg.CompartmentID != "" {
+		reqBody.LaunchDetails.CompartmentID = c.cfg.CompartmentID
+	}
+	if c.cfg.CountTagKey != "" {
+		tags, err := c.countedInstanceConfigurationTags()
+		if err != nil {
+			return nil, "", err
+		}
+		reqBody.LaunchDetails.FreeformTags = tags
+	}
+
+	path := fmt.Sprintf("/instanceConfigurations/%s/actions/launch", c.cfg.InstanceConfigurationID)
+	respBody, header, err := c.buildAndDoWithHeaders(serviceCompute, http.MethodPost, path, nil, reqBody)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var instance Instance
+	if err := json.Unmarshal(respBody, &instance); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal launch instance configuration response: %w", err)
+	}
+	return &instance, header.Get("opc-work-request-id"), nil
+}
+
+// countedInstanceConfigurationTags returns the freeform tags of the instance
+// configuration with the count tag added. Overriding the tags replaces them
+// as a whole, so the configured ones are fetched once and kept.
+func (c *Client) countedInstanceConfigurationTags() (map[string]string, error) {
+	if c.instanceConfigurationTags != nil {
+		return c.instanceConfigurationTags, nil
+	}
+	configuration, err := c.GetInstanceConfiguration(c.cfg.InstanceConfigurationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instance configuration: %w", err)
+	}
+
+	tags := map[string]string{}
+	if details := configuration.InstanceDetails; details != nil && details.LaunchDetails != nil {
+		maps.Copy(tags, details.LaunchDetails.FreeformTags)
+	}
+	tags[c.cfg.CountTagKey] = c.cfg.CountTagValue
+	c.instanceConfigurationTags = tags
+	return tags, nil
+}
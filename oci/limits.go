@@ -0,0 +1,62 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GetResourceAvailability fetches the usage and availability of a compute
+// service limit in an availability domain. The result accounts for any
+// compartment quotas that apply to compartmentID.
+func (c *Client) GetResourceAvailability(limitName, compartmentID, availabilityDomain string) (*ResourceAvailability, error) {
+	params := url.Values{}
+	params.Add("compartmentId", compartmentID)
+	if availabilityDomain != "" {
+		params.Add("availabilityDomain", availabilityDomain)
+	}
+
+	path := fmt.Sprintf("/services/compute/limits/%s/resourceAvailability", url.PathEscape(limitName))
+	respBody, err := c.buildAndDo(serviceLimits, http.MethodGet, path, params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var availability ResourceAvailability
+	if err := json.Unmarshal(respBody, &availability); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal resource availability response: %w", err)
+	}
+	return &availability, nil
+}
+
+// ListQuotas fetches the quota policies defined in a compartment.
+func (c *Client) ListQuotas(compartmentID string) ([]QuotaSummary, error) {
+	params := url.Values{}
+	params.Add("compartmentId", compartmentID)
+
+	respBody, err := c.buildAndDo(serviceQuotas, http.MethodGet, "/quotas", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var quotas []QuotaSummary
+	if err := json.Unmarshal(respBody, &quotas); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal quotas response: %w", err)
+	}
+	return quotas, nil
+}
+
+// GetQuota fetches a quota policy including its statements.
+func (c *Client) GetQuota(quotaID string) (*Quota, error) {
+	respBody, err := c.buildAndDo(serviceQuotas, http.MethodGet, "/quotas/"+quotaID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var quota Quota
+	if err := json.Unmarshal(respBody, &quota); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal quota response: %w", err)
+	}
+	return &quota, nil
+}
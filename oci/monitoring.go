@@ -0,0 +1,35 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SummarizeMetricsData runs a Monitoring Query Language query over a time
+// range, e.g. `CpuUtilization[5m]{resourceId = "<ocid>"}.mean()` in the
+// oci_computeagent namespace.
+func (c *Client) SummarizeMetricsData(compartmentID, namespace, query string, start, end time.Time) ([]MetricData, error) {
+	params := url.Values{}
+	params.Add("compartmentId", compartmentID)
+
+	reqBody := SummarizeMetricsDataDetails{
+		Namespace: namespace,
+		Query:     query,
+		StartTime: start.UTC().Format(time.RFC3339),
+		EndTime:   end.UTC().Format(time.RFC3339),
+	}
+
+	respBody, err := c.buildAndDo(serviceMonitoring, http.MethodPost, "/metrics/actions/summarizeMetricsData", params, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []MetricData
+	if err := json.Unmarshal(respBody, &metrics); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metrics response: %w", err)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,87 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// GetSubnet fetches a subnet by OCID.
+func (c *Client) GetSubnet(subnetID string) (*Subnet, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/subnets/"+subnetID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var subnet Subnet
+	if err := json.Unmarshal(respBody, &subnet); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal subnet response: %w", err)
+	}
+	return &subnet, nil
+}
+
+// ListVnicAttachments fetches the VNIC attachments of an instance.
+func (c *Client) ListVnicAttachments(instanceID string) ([]VnicAttachment, error) {
+	params := url.Values{}
+	params.Add("compartmentId", c.cfg.LaunchCompartmentID())
+	params.Add("instanceId", instanceID)
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/vnicAttachments/", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []VnicAttachment
+	if err := json.Unmarshal(respBody, &attachments); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal VNIC attachments response: %w", err)
+	}
+	return attachments, nil
+}
+
+// GetVnic fetches a VNIC by OCID.
+func (c *Client) GetVnic(vnicID string) (*Vnic, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/vnics/"+vnicID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var vnic Vnic
+	if err := json.Unmarshal(respBody, &vnic); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal VNIC response: %w", err)
+	}
+	return &vnic, nil
+}
+
+// ListPrivateIPs fetches the private IPs assigned to a VNIC.
+func (c *Client) ListPrivateIPs(vnicID string) ([]PrivateIP, error) {
+	params := url.Values{}
+	params.Add("vnicId", vnicID)
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/privateIps", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var privateIPs []PrivateIP
+	if err := json.Unmarshal(respBody, &privateIPs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private IPs response: %w", err)
+	}
+	return privateIPs, nil
+}
+
+// UpdatePublicIP assigns a reserved public IP to a private IP.
+func (c *Client) UpdatePublicIP(publicIPID, privateIPID string) (*PublicIP, error) {
+	reqBody := UpdatePublicIPDetails{PrivateIPID: privateIPID}
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodPut, "/publicIps/"+publicIPID, nil, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicIP PublicIP
+	if err := json.Unmarshal(respBody, &publicIP); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal public IP response: %w", err)
+	}
+	return &publicIP, nil
+}
@@ -0,0 +1,66 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CreateComputeCapacityReservation reserves reservedCount instances of the
+// given shape configuration in an availability domain.
+func (c *Client) CreateComputeCapacityReservation(availabilityDomain string, shapeConfig ShapeConfig, reservedCount int) (*ComputeCapacityReservation, error) {
+	reqBody := CreateComputeCapacityReservationDetails{
+		CompartmentID:      c.cfg.LaunchCompartmentID(),
+		AvailabilityDomain: availabilityDomain,
+		DisplayName:        fmt.Sprintf("reservation-%s", time.Now().Format("20060102-1504")),
+		InstanceReservationConfigs: []InstanceReservationConfig{{
+			InstanceShape:       c.cfg.Shape,
+			InstanceShapeConfig: &shapeConfig,
+			ReservedCount:       int64(reservedCount),
+		}},
+	}
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodPost, "/computeCapacityReservations", nil, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var reservation ComputeCapacityReservation
+	if err := json.Unmarshal(respBody, &reservation); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal capacity reservation response: %w", err)
+	}
+	return &reservation, nil
+}
+
+// ListComputeCapacityReservations fetches the capacity reservations in the compartment.
+func (c *Client) ListComputeCapacityReservations() ([]ComputeCapacityReservation, error) {
+	params := url.Values{}
+	params.Add("compartmentId", c.cfg.LaunchCompartmentID())
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/computeCapacityReservations", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var reservations []ComputeCapacityReservation
+	if err := json.Unmarshal(respBody, &reservations); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal capacity reservations response: %w", err)
+	}
+	return reservations, nil
+}
+
+// GetComputeCapacityReservation fetches a capacity reservation by OCID.
+func (c *Client) GetComputeCapacityReservation(reservationID string) (*ComputeCapacityReservation, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/computeCapacityReservations/"+reservationID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var reservation ComputeCapacityReservation
+	if err := json.Unmarshal(respBody, &reservation); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal capacity reservation response: %w", err)
+	}
+	return &reservation, nil
+}
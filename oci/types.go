@@ -4,12 +4,16 @@ package oci
 
 // Instance represents an OCI compute instance.
 type Instance struct {
-	ID                 string `json:"id"`
-	AvailabilityDomain string `json:"availabilityDomain"`
-	CompartmentID      string `json:"compartmentId"`
-	DisplayName        string `json:"displayName"`
-	Shape              string `json:"shape"`
-	LifecycleState     string `json:"lifecycleState"`
+	ID                 string                            `json:"id"`
+	AvailabilityDomain string                            `json:"availabilityDomain"`
+	CompartmentID      string                            `json:"compartmentId"`
+	DisplayName        string                            `json:"displayName"`
+	FaultDomain        string                            `json:"faultDomain,omitempty"`
+	Shape              string                            `json:"shape"`
+	LifecycleState     string                            `json:"lifecycleState"`
+	ShapeConfig        *ShapeConfig                      `json:"shapeConfig,omitempty"`
+	FreeformTags       map[string]string                 `json:"freeformTags,omitempty"`
+	DefinedTags        map[string]map[string]interface{} `json:"definedTags,omitempty"`
 }
 
 // AvailabilityDomain represents an OCI availability domain.
@@ -21,25 +25,423 @@ type AvailabilityDomain struct {
 
 // CreateInstanceDetails is the request body for launching an instance.
 type CreateInstanceDetails struct {
-	AvailabilityDomain string                 `json:"availabilityDomain"`
-	CompartmentID      string                 `json:"compartmentId"`
-	Shape              string                 `json:"shape"`
-	DisplayName        string                 `json:"displayName"`
-	Metadata           map[string]string      `json:"metadata"`
-	SourceDetails      map[string]interface{} `json:"sourceDetails"`
-	CreateVnicDetails  *VnicDetails           `json:"createVnicDetails,omitempty"`
-	ShapeConfig        *ShapeConfig           `json:"shapeConfig,omitempty"`
+	AvailabilityDomain    string                            `json:"availabilityDomain"`
+	CompartmentID         string                            `json:"compartmentId"`
+	Shape                 string                            `json:"shape"`
+	DisplayName           string                            `json:"displayName"`
+	Metadata              map[string]string                 `json:"metadata"`
+	ExtendedMetadata      map[string]interface{}            `json:"extendedMetadata,omitempty"`
+	SourceDetails         map[string]interface{}            `json:"sourceDetails"`
+	CreateVnicDetails     *VnicDetails                      `json:"createVnicDetails,omitempty"`
+	ShapeConfig           *ShapeConfig                      `json:"shapeConfig,omitempty"`
+	CapacityReservationID string                            `json:"capacityReservationId,omitempty"`
+	FreeformTags          map[string]string                 `json:"freeformTags,omitempty"`
+	DefinedTags           map[string]map[string]interface{} `json:"definedTags,omitempty"`
+	FaultDomain           string                            `json:"faultDomain,omitempty"`
+	LaunchOptions         *LaunchOptions                    `json:"launchOptions,omitempty"`
+	PlatformConfig        *PlatformConfig                   `json:"platformConfig,omitempty"`
+	InstanceOptions       *InstanceOptions                  `json:"instanceOptions,omitempty"`
+	AvailabilityConfig    *AvailabilityConfig               `json:"availabilityConfig,omitempty"`
+	AgentConfig           *AgentConfig                      `json:"agentConfig,omitempty"`
+}
+
+// AgentConfig configures the Oracle Cloud Agent and its plugins.
+type AgentConfig struct {
+	IsMonitoringDisabled  bool                `json:"isMonitoringDisabled"`
+	IsManagementDisabled  bool                `json:"isManagementDisabled"`
+	AreAllPluginsDisabled bool                `json:"areAllPluginsDisabled"`
+	PluginsConfig         []AgentPluginConfig `json:"pluginsConfig,omitempty"`
+}
+
+// AgentPluginConfig is the desired state of one Cloud Agent plugin.
+type AgentPluginConfig struct {
+	Name         string `json:"name"`
+	DesiredState string `json:"desiredState"`
+}
+
+// LaunchOptions selects the firmware and emulation types used at launch.
+type LaunchOptions struct {
+	Firmware                       string `json:"firmware,omitempty"`
+	NetworkType                    string `json:"networkType,omitempty"`
+	BootVolumeType                 string `json:"bootVolumeType,omitempty"`
+	IsPvEncryptionInTransitEnabled bool   `json:"isPvEncryptionInTransitEnabled,omitempty"`
+}
+
+// PlatformConfig enables Shielded Instance features.
+type PlatformConfig struct {
+	Type                           string `json:"type"`
+	IsSecureBootEnabled            bool   `json:"isSecureBootEnabled"`
+	IsMeasuredBootEnabled          bool   `json:"isMeasuredBootEnabled"`
+	IsTrustedPlatformModuleEnabled bool   `json:"isTrustedPlatformModuleEnabled"`
+}
+
+// InstanceOptions holds optional instance behaviour settings.
+type InstanceOptions struct {
+	AreLegacyImdsEndpointsDisabled bool `json:"areLegacyImdsEndpointsDisabled"`
+}
+
+// AvailabilityConfig controls what happens during infrastructure maintenance.
+type AvailabilityConfig struct {
+	RecoveryAction           string `json:"recoveryAction,omitempty"`
+	IsLiveMigrationPreferred *bool  `json:"isLiveMigrationPreferred,omitempty"`
+}
+
+// LaunchParams holds the per-attempt settings for CreateInstance. Everything
+// else is taken from the client configuration.
+type LaunchParams struct {
+	AvailabilityDomain    string
+	ShapeConfig           ShapeConfig
+	CapacityReservationID string // Optional
+	FaultDomain           string // Optional
+	BootVolumeID          string // Optional, overrides the configured boot volume
 }
 
 // VnicDetails for instance network interface.
 type VnicDetails struct {
-	SubnetID               string `json:"subnetId"`
-	AssignPublicIP         bool   `json:"assignPublicIp"`
-	AssignPrivateDNSRecord bool   `json:"assignPrivateDnsRecord"`
+	SubnetID               string   `json:"subnetId"`
+	AssignPublicIP         bool     `json:"assignPublicIp"`
+	AssignPrivateDNSRecord bool     `json:"assignPrivateDnsRecord"`
+	AssignIpv6IP           bool     `json:"assignIpv6Ip,omitempty"`
+	DisplayName            string   `json:"displayName,omitempty"`
+	HostnameLabel          string   `json:"hostnameLabel,omitempty"`
+	NsgIDs                 []string `json:"nsgIds,omitempty"`
+	PrivateIP              string   `json:"privateIp,omitempty"`
+	SkipSourceDestCheck    bool     `json:"skipSourceDestCheck,omitempty"`
 }
 
 // ShapeConfig for flexible instance shapes.
 type ShapeConfig struct {
-	Ocpus       int `json:"ocpus"`
-	MemoryInGBs int `json:"memoryInGBs"`
+	Ocpus       float32 `json:"ocpus"`
+	MemoryInGBs float32 `json:"memoryInGBs"`
+}
+
+// UpdateInstanceDetails is the request body for updating an instance.
+type UpdateInstanceDetails struct {
+	ShapeConfig *ShapeConfig `json:"shapeConfig,omitempty"`
+}
+
+// ResourceAvailability is the usage and availability of a service limit.
+type ResourceAvailability struct {
+	Used                int64    `json:"used"`
+	Available           int64    `json:"available"`
+	EffectiveQuotaValue *float64 `json:"effectiveQuotaValue,omitempty"`
+}
+
+// QuotaSummary is an entry in the list of compartment quota policies.
+type QuotaSummary struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	LifecycleState string `json:"lifecycleState"`
+}
+
+// Quota is a compartment quota policy with its statements.
+type Quota struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Statements []string `json:"statements"`
+}
+
+// CreateCapacityReportShapeAvailabilityDetails is a shape configuration to check in a capacity report.
+type CreateCapacityReportShapeAvailabilityDetails struct {
+	InstanceShape       string       `json:"instanceShape"`
+	InstanceShapeConfig *ShapeConfig `json:"instanceShapeConfig,omitempty"`
+	FaultDomain         string       `json:"faultDomain,omitempty"`
+}
+
+// CreateComputeCapacityReportDetails is the request body for a compute capacity report.
+type CreateComputeCapacityReportDetails struct {
+	CompartmentID       string                                         `json:"compartmentId"`
+	AvailabilityDomain  string                                         `json:"availabilityDomain"`
+	ShapeAvailabilities []CreateCapacityReportShapeAvailabilityDetails `json:"shapeAvailabilities"`
+}
+
+// CapacityReportShapeAvailability is the reported availability of one shape configuration.
+type CapacityReportShapeAvailability struct {
+	InstanceShape       string       `json:"instanceShape"`
+	InstanceShapeConfig *ShapeConfig `json:"instanceShapeConfig,omitempty"`
+	FaultDomain         string       `json:"faultDomain,omitempty"`
+	AvailableCount      int64        `json:"availableCount"`
+	AvailabilityStatus  string       `json:"availabilityStatus"`
+}
+
+// ComputeCapacityReport is the result of a compute capacity report.
+type ComputeCapacityReport struct {
+	CompartmentID       string                            `json:"compartmentId"`
+	AvailabilityDomain  string                            `json:"availabilityDomain"`
+	ShapeAvailabilities []CapacityReportShapeAvailability `json:"shapeAvailabilities"`
+	TimeCreated         string                            `json:"timeCreated"`
+}
+
+// InstanceReservationConfig is the shape configuration reserved by a capacity reservation.
+type InstanceReservationConfig struct {
+	InstanceShape       string       `json:"instanceShape"`
+	InstanceShapeConfig *ShapeConfig `json:"instanceShapeConfig,omitempty"`
+	FaultDomain         string       `json:"faultDomain,omitempty"`
+	ReservedCount       int64        `json:"reservedCount"`
+	UsedCount           int64        `json:"usedCount,omitempty"`
+}
+
+// ComputeCapacityReservation is a block of compute capacity held for future launches.
+type ComputeCapacityReservation struct {
+	ID                         string                      `json:"id"`
+	CompartmentID              string                      `json:"compartmentId"`
+	AvailabilityDomain         string                      `json:"availabilityDomain"`
+	DisplayName                string                      `json:"displayName"`
+	LifecycleState             string                      `json:"lifecycleState"`
+	ReservedInstanceCount      int64                       `json:"reservedInstanceCount"`
+	UsedInstanceCount          int64                       `json:"usedInstanceCount"`
+	InstanceReservationConfigs []InstanceReservationConfig `json:"instanceReservationConfigs"`
+}
+
+// CreateComputeCapacityReservationDetails is the request body for creating a capacity reservation.
+type CreateComputeCapacityReservationDetails struct {
+	CompartmentID              string                      `json:"compartmentId"`
+	AvailabilityDomain         string                      `json:"availabilityDomain"`
+	DisplayName                string                      `json:"displayName"`
+	IsDefaultReservation       bool                        `json:"isDefaultReservation"`
+	InstanceReservationConfigs []InstanceReservationConfig `json:"instanceReservationConfigs"`
+}
+
+// VnicAttachment links a VNIC to an instance.
+type VnicAttachment struct {
+	ID             string `json:"id"`
+	InstanceID     string `json:"instanceId"`
+	VnicID         string `json:"vnicId"`
+	LifecycleState string `json:"lifecycleState"`
+}
+
+// Vnic is a virtual network interface card.
+type Vnic struct {
+	ID        string `json:"id"`
+	IsPrimary bool   `json:"isPrimary"`
+	PrivateIP string `json:"privateIp"`
+	PublicIP  string `json:"publicIp,omitempty"`
+}
+
+// PrivateIP is a private IP address on a VNIC.
+type PrivateIP struct {
+	ID        string `json:"id"`
+	IPAddress string `json:"ipAddress"`
+	IsPrimary bool   `json:"isPrimary"`
+	VnicID    string `json:"vnicId"`
+}
+
+// PublicIP is an ephemeral or reserved public IP address.
+type PublicIP struct {
+	ID             string `json:"id"`
+	IPAddress      string `json:"ipAddress"`
+	Lifetime       string `json:"lifetime"`
+	LifecycleState string `json:"lifecycleState"`
+	PrivateIPID    string `json:"privateIpId,omitempty"`
+}
+
+// UpdatePublicIPDetails is the request body for assigning a public IP to a private IP.
+type UpdatePublicIPDetails struct {
+	PrivateIPID string `json:"privateIpId"`
+}
+
+// Subnet is a VCN subnet.
+type Subnet struct {
+	ID                     string   `json:"id"`
+	DisplayName            string   `json:"displayName"`
+	AvailabilityDomain     string   `json:"availabilityDomain,omitempty"`
+	CidrBlock              string   `json:"cidrBlock"`
+	Ipv6CidrBlocks         []string `json:"ipv6CidrBlocks,omitempty"`
+	DNSLabel               string   `json:"dnsLabel,omitempty"`
+	ProhibitPublicIPOnVnic bool     `json:"prohibitPublicIpOnVnic"`
+}
+
+// Compartment is an IAM compartment.
+type Compartment struct {
+	ID             string `json:"id"`
+	CompartmentID  string `json:"compartmentId"`
+	Name           string `json:"name"`
+	LifecycleState string `json:"lifecycleState"`
+}
+
+// Image is a compute image.
+type Image struct {
+	ID                     string `json:"id"`
+	DisplayName            string `json:"displayName"`
+	OperatingSystem        string `json:"operatingSystem"`
+	OperatingSystemVersion string `json:"operatingSystemVersion"`
+	LifecycleState         string `json:"lifecycleState"`
+	TimeCreated            string `json:"timeCreated"`
+}
+
+// InstanceConfigurationLaunchDetails overrides parts of an instance
+// configuration's launch details. Unset fields keep the configured values.
+type InstanceConfigurationLaunchDetails struct {
+	AvailabilityDomain    string                                  `json:"availabilityDomain"`
+	CompartmentID         string                                  `json:"compartmentId,omitempty"`
+	FaultDomain           string                                  `json:"faultDomain,omitempty"`
+	Shape                 string                                  `json:"shape,omitempty"`
+	ShapeConfig           *ShapeConfig                            `json:"shapeConfig,omitempty"`
+	CapacityReservationID string                                  `json:"capacityReservationId,omitempty"`
+	CreateVnicDetails     *InstanceConfigurationCreateVnicDetails `json:"createVnicDetails,omitempty"`
+	FreeformTags          map[string]string                       `json:"freeformTags,omitempty"`
+}
+
+// InstanceConfigurationCreateVnicDetails overrides the primary VNIC subnet of an instance configuration.
+type InstanceConfigurationCreateVnicDetails struct {
+	SubnetID string `json:"subnetId,omitempty"`
+}
+
+// ComputeInstanceDetails is the request body for launching an instance configuration.
+type ComputeInstanceDetails struct {
+	InstanceType  string                              `json:"instanceType"`
+	LaunchDetails *InstanceConfigurationLaunchDetails `json:"launchDetails,omitempty"`
+}
+
+// InstanceConfiguration is a saved set of instance launch details.
+type InstanceConfiguration struct {
+	ID              string                  `json:"id"`
+	DisplayName     string                  `json:"displayName"`
+	InstanceDetails *ComputeInstanceDetails `json:"instanceDetails"`
+}
+
+// WorkRequest tracks the progress of an asynchronous operation.
+type WorkRequest struct {
+	ID              string  `json:"id"`
+	OperationType   string  `json:"operationType"`
+	Status          string  `json:"status"`
+	PercentComplete float32 `json:"percentComplete"`
+	TimeAccepted    string  `json:"timeAccepted"`
+	TimeFinished    string  `json:"timeFinished,omitempty"`
+}
+
+// WorkRequestError is an error reported by a failed work request.
+type WorkRequestError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Timestamp string `json:"timestamp"`
+}
+
+// Volume is a block volume.
+type Volume struct {
+	ID                 string `json:"id"`
+	AvailabilityDomain string `json:"availabilityDomain"`
+	DisplayName        string `json:"displayName"`
+	LifecycleState     string `json:"lifecycleState"`
+	SizeInGBs          int64  `json:"sizeInGBs"`
+	VpusPerGB          int64  `json:"vpusPerGB"`
+}
+
+// CreateVolumeDetails is the request body for creating a block volume.
+type CreateVolumeDetails struct {
+	AvailabilityDomain string                            `json:"availabilityDomain"`
+	CompartmentID      string                            `json:"compartmentId"`
+	DisplayName        string                            `json:"displayName,omitempty"`
+	SizeInGBs          int64                             `json:"sizeInGBs"`
+	VpusPerGB          *int64                            `json:"vpusPerGB,omitempty"`
+	FreeformTags       map[string]string                 `json:"freeformTags,omitempty"`
+	DefinedTags        map[string]map[string]interface{} `json:"definedTags,omitempty"`
+}
+
+// AttachVolumeDetails is the request body for attaching a block volume.
+type AttachVolumeDetails struct {
+	Type        string `json:"type"`
+	InstanceID  string `json:"instanceId"`
+	VolumeID    string `json:"volumeId"`
+	DisplayName string `json:"displayName,omitempty"`
+	IsReadOnly  bool   `json:"isReadOnly,omitempty"`
+}
+
+// VolumeAttachment links a block volume to an instance. The iSCSI fields are
+// only set for iSCSI attachments.
+type VolumeAttachment struct {
+	ID             string `json:"id"`
+	AttachmentType string `json:"attachmentType"`
+	InstanceID     string `json:"instanceId"`
+	VolumeID       string `json:"volumeId"`
+	DisplayName    string `json:"displayName"`
+	LifecycleState string `json:"lifecycleState"`
+	Device         string `json:"device,omitempty"`
+	IsReadOnly     bool   `json:"isReadOnly"`
+	Iqn            string `json:"iqn,omitempty"`
+	Ipv4           string `json:"ipv4,omitempty"`
+	Port           int    `json:"port,omitempty"`
+}
+
+// BootVolumeAttachment links a boot volume to an instance.
+type BootVolumeAttachment struct {
+	ID                 string `json:"id"`
+	AvailabilityDomain string `json:"availabilityDomain"`
+	BootVolumeID       string `json:"bootVolumeId"`
+	InstanceID         string `json:"instanceId"`
+	LifecycleState     string `json:"lifecycleState"`
+}
+
+// BootVolume is an instance boot volume.
+type BootVolume struct {
+	ID                 string `json:"id"`
+	AvailabilityDomain string `json:"availabilityDomain"`
+	DisplayName        string `json:"displayName"`
+	LifecycleState     string `json:"lifecycleState"`
+	SizeInGBs          int64  `json:"sizeInGBs"`
+}
+
+// BootVolumeSourceDetails is the source a boot volume is created from.
+type BootVolumeSourceDetails struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// CreateBootVolumeDetails is the request body for creating a boot volume.
+type CreateBootVolumeDetails struct {
+	AvailabilityDomain string                  `json:"availabilityDomain"`
+	CompartmentID      string                  `json:"compartmentId"`
+	DisplayName        string                  `json:"displayName,omitempty"`
+	SizeInGBs          int64                   `json:"sizeInGBs,omitempty"`
+	SourceDetails      BootVolumeSourceDetails `json:"sourceDetails"`
+}
+
+// BootVolumeBackup is a point-in-time copy of a boot volume. Unlike boot
+// volumes, backups are not bound to an availability domain.
+type BootVolumeBackup struct {
+	ID             string `json:"id"`
+	BootVolumeID   string `json:"bootVolumeId"`
+	DisplayName    string `json:"displayName"`
+	LifecycleState string `json:"lifecycleState"`
+	Type           string `json:"type"`
+	SizeInGBs      int64  `json:"sizeInGBs,omitempty"`
+	TimeCreated    string `json:"timeCreated"`
+}
+
+// CreateBootVolumeBackupDetails is the request body for backing up a boot volume.
+type CreateBootVolumeBackupDetails struct {
+	BootVolumeID string `json:"bootVolumeId"`
+	DisplayName  string `json:"displayName,omitempty"`
+	Type         string `json:"type,omitempty"`
+}
+
+// SummarizeMetricsDataDetails is the request body for querying metrics.
+type SummarizeMetricsDataDetails struct {
+	Namespace  string `json:"namespace"`
+	Query      string `json:"query"`
+	StartTime  string `json:"startTime"`
+	EndTime    string `json:"endTime"`
+	Resolution string `json:"resolution,omitempty"`
+}
+
+// MetricData is one metric stream returned by a metrics query.
+type MetricData struct {
+	Namespace            string            `json:"namespace"`
+	Name                 string            `json:"name"`
+	Dimensions           map[string]string `json:"dimensions"`
+	AggregatedDatapoints []Datapoint       `json:"aggregatedDatapoints"`
+}
+
+// Datapoint is an aggregated metric value.
+type Datapoint struct {
+	Timestamp string  `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// RegionSubscription is a region the tenancy is subscribed to.
+type RegionSubscription struct {
+	RegionKey    string `json:"regionKey"`
+	RegionName   string `json:"regionName"`
+	Status       string `json:"status"`
+	IsHomeRegion bool   `json:"isHomeRegion"`
 }
@@ -0,0 +1,79 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CreateVolume creates a block volume in the launch compartment.
+func (c *Client) CreateVolume(details CreateVolumeDetails) (*Volume, error) {
+	details.CompartmentID = c.cfg.LaunchCompartmentID()
+
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodPost, "/volumes", nil, details)
+	if err != nil {
+		return nil, err
+	}
+
+	var volume Volume
+	if err := json.Unmarshal(respBody, &volume); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal create volume response: %w", err)
+	}
+	return &volume, nil
+}
+
+// ListVolumes fetches the block volumes in a compartment.
+func (c *Client) ListVolumes(compartmentID string) ([]Volume, error) {
+	params := url.Values{}
+	params.Add("compartmentId", compartmentID)
+
+	volumes, err := listAll[Volume](c, serviceCompute, "/volumes", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", err)
+	}
+	return volumes, nil
+}
+
+// GetVolume fetches a block volume by OCID.
+func (c *Client) GetVolume(volumeID string) (*Volume, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/volumes/"+volumeID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var volume Volume
+	if err := json.Unmarshal(respBody, &volume); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal volume response: %w", err)
+	}
+	return &volume, nil
+}
+
+// AttachVolume attaches a block volume to an instance. It returns the
+// attachment and the OCID of the work request tracking it, if OCI reports one.
+func (c *Client) AttachVolume(details AttachVolumeDetails) (*VolumeAttachment, string, error) {
+	respBody, header, err := c.buildAndDoWithHeaders(serviceCompute, http.MethodPost, "/volumeAttachments/", nil, details)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var attachment VolumeAttachment
+	if err := json.Unmarshal(respBody, &attachment); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal attach volume response: %w", err)
+	}
+	return &attachment, header.Get("opc-work-request-id"), nil
+}
+
+// GetVolumeAttachment fetches a volume attachment by OCID.
+func (c *Client) GetVolumeAttachment(attachmentID string) (*VolumeAttachment, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/volumeAttachments/"+attachmentID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachment VolumeAttachment
+	if err := json.Unmarshal(respBody, &attachment); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal volume attachment response: %w", err)
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,90 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// workRequestWaitAttempts bounds how many times a work request is polled
+// before giving up on it. Each poll is paced by the client.
+const workRequestWaitAttempts = 45
+
+// WorkRequestFailedError is returned when a work request finishes without
+// succeeding. It carries the errors the work request reported.
+type WorkRequestFailedError struct {
+	WorkRequest *WorkRequest
+	Errors      []WorkRequestError
+}
+
+func (e *WorkRequestFailedError) Error() string {
+	msg := fmt.Sprintf("work request %s (%s) %s", e.WorkRequest.ID, e.WorkRequest.OperationType, e.WorkRequest.Status)
+	if len(e.Errors) == 0 {
+		return msg
+	}
+	messages := make([]string, len(e.Errors))
+	for i, wrErr := range e.Errors {
+		messages[i] = fmt.Sprintf("%s - %s", wrErr.Code, wrErr.Message)
+	}
+	return msg + ": " + strings.Join(messages, "; ")
+}
+
+// GetWorkRequest fetches the status of an asynchronous operation.
+func (c *Client) GetWorkRequest(workRequestID string) (*WorkRequest, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/workRequests/"+workRequestID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var workRequest WorkRequest
+	if err := json.Unmarshal(respBody, &workRequest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal work request response: %w", err)
+	}
+	return &workRequest, nil
+}
+
+// ListWorkRequestErrors fetches the errors reported by a work request.
+func (c *Client) ListWorkRequestErrors(workRequestID string) ([]WorkRequestError, error) {
+	respBody, err := c.buildAndDo(serviceCompute, http.MethodGet, "/workRequests/"+workRequestID+"/errors", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var wrErrors []WorkRequestError
+	if err := json.Unmarshal(respBody, &wrErrors); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal work request errors response: %w", err)
+	}
+	return wrErrors, nil
+}
+
+// WaitForWorkRequest polls a work request until it finishes, logging its
+// progress. If it does not succeed, the returned error is a
+// *WorkRequestFailedError with the errors the work request reported.
+func (c *Client) WaitForWorkRequest(workRequestID string) (*WorkRequest, error) {
+	var lastStatus string
+	lastPercent := float32(-1)
+	for i := 0; i < workRequestWaitAttempts; i++ {
+		workRequest, err := c.GetWorkRequest(workRequestID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get work request %s: %w", workRequestID, err)
+		}
+		if workRequest.Status != lastStatus || workRequest.PercentComplete != lastPercent {
+			log.Printf("Work request %s (%s): %s, %.0f%% complete.", workRequest.ID, workRequest.OperationType, workRequest.Status, workRequest.PercentComplete)
+			lastStatus, lastPercent = workRequest.Status, workRequest.PercentComplete
+		}
+
+		switch workRequest.Status {
+		case "SUCCEEDED":
+			return workRequest, nil
+		case "FAILED", "CANCELED":
+			failed := &WorkRequestFailedError{WorkRequest: workRequest}
+			if failed.Errors, err = c.ListWorkRequestErrors(workRequest.ID); err != nil {
+				log.Printf("Warning: could not list errors of work request %s: %v", workRequest.ID, err)
+			}
+			return workRequest, failed
+		}
+	}
+	return nil, fmt.Errorf("work request %s did not finish in time", workRequestID)
+}
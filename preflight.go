@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net/netip"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// preflight verifies settings that depend on existing OCI resources before
+// the main loop starts, so that mistakes surface once instead of on every
+// launch attempt.
+func preflight(client *oci.Client, cfg *config.Config) error {
+	if err := checkSubnet(client, cfg); err != nil {
+		return fmt.Errorf("subnet %s: %w", cfg.SubnetID, err)
+	}
+	if err := checkInstanceConfiguration(client, cfg); err != nil {
+		return fmt.Errorf("instance configuration %s: %w", cfg.InstanceConfigurationID, err)
+	}
+	return nil
+}
+
+// checkInstanceConfiguration verifies that the instance configuration
+// launches OCI_SHAPE, the shape launched instances are counted by.
+func checkInstanceConfiguration(client *oci.Client, cfg *config.Config) error {
+	if cfg.InstanceConfigurationID == "" {
+		return nil
+	}
+
+	configuration, err := client.GetInstanceConfiguration(cfg.InstanceConfigurationID)
+	if err != nil {
+		return fmt.Errorf("failed to get instance configuration: %w", err)
+	}
+	details := configuration.InstanceDetails
+	if details == nil || details.LaunchDetails == nil {
+		return fmt.Errorf("the instance configuration has no compute launch details")
+	}
+	if shape := details.LaunchDetails.Shape; shape != cfg.Shape {
+		return fmt.Errorf("the instance configuration launches %s, but OCI_SHAPE is %s; set OCI_SHAPE=%s so that its instances are counted", shape, cfg.Shape, shape)
+	}
+	log.Printf("Preflight: instance configuration %s (%s).", configuration.DisplayName, details.LaunchDetails.Shape)
+	return nil
+}
+
+// checkSubnet validates the VNIC settings against the configured subnet.
+func checkSubnet(client *oci.Client, cfg *config.Config) error {
+	needsSubnet := cfg.PrivateIP != "" || cfg.AssignIpv6 || cfg.HostnameLabel != "" ||
+		cfg.AssignPublicIP || cfg.ReservedPublicIPID != ""
+	if !needsSubnet {
+		return nil
+	}
+
+	subnet, err := client.GetSubnet(cfg.SubnetID)
+	if err != nil {
+		return fmt.Errorf("failed to get subnet: %w", err)
+	}
+	log.Printf("Preflight: subnet %s (%s).", subnet.DisplayName, subnet.CidrBlock)
+
+	var errs []error
+	if cfg.PrivateIP != "" {
+		if err := checkPrivateIP(cfg.PrivateIP, subnet.CidrBlock); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if cfg.AssignIpv6 && len(subnet.Ipv6CidrBlocks) == 0 {
+		errs = append(errs, fmt.Errorf("OCI_ASSIGN_IPV6 is set, but the subnet has no IPv6 prefix"))
+	}
+	if cfg.HostnameLabel != "" && subnet.DNSLabel == "" {
+		errs = append(errs, fmt.Errorf("OCI_HOSTNAME_LABEL is set, but DNS hostnames are not enabled for the subnet"))
+	}
+	if (cfg.AssignPublicIP || cfg.ReservedPublicIPID != "") && subnet.ProhibitPublicIPOnVnic {
+		errs = append(errs, fmt.Errorf("a public IP is requested, but the subnet is private"))
+	}
+	return errors.Join(errs...)
+}
+
+// checkPrivateIP verifies that ip is a usable host address within cidr. OCI
+// reserves the network address, the first host address and the broadcast address.
+func checkPrivateIP(ip, cidr string) error {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return fmt.Errorf("OCI_PRIVATE_IP %q is not a valid address: %w", ip, err)
+	}
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return fmt.Errorf("failed to parse subnet CIDR %q: %w", cidr, err)
+	}
+	prefix = prefix.Masked()
+	if !prefix.Contains(addr) {
+		return fmt.Errorf("OCI_PRIVATE_IP %s is outside the subnet CIDR %s", ip, prefix)
+	}
+
+	network := prefix.Addr()
+	bytes := network.As4()
+	last := binary.BigEndian.Uint32(bytes[:]) | (1<<(32-prefix.Bits()) - 1)
+	binary.BigEndian.PutUint32(bytes[:], last)
+	broadcast := netip.AddrFrom4(bytes)
+	if addr == network || addr == network.Next() || addr == broadcast {
+		return fmt.Errorf("OCI_PRIVATE_IP %s is reserved by OCI in subnet %s", ip, prefix)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// instanceWaitAttempts bounds how many times an instance is polled while
+// waiting for it to reach RUNNING. Each poll is paced by the client.
+const instanceWaitAttempts = 45
+
+// postLaunch runs the provisioning steps that need a running instance and
+// returns a summary of them for the success notification. Failures are
+// logged and reported in the summary, but do not undo the launch.
+func (f *finder) postLaunch(instance *oci.Instance) string {
+	if !f.hasPostLaunchSteps() {
+		return ""
+	}
+
+	f.state.SetPhase(fmt.Sprintf("waiting for %s to start", instance.DisplayName))
+	running, err := f.waitForInstanceRunning(instance.ID)
+	if err != nil {
+		log.Printf("Post-launch: %v", err)
+		return fmt.Sprintf("Post-launch setup skipped: %v", err)
+	}
+
+	var summary []string
+	if f.cfg.ReservedPublicIPID != "" {
+		f.state.SetPhase(fmt.Sprintf("attaching public IP to %s", running.DisplayName))
+		publicIP, err := f.attachReservedPublicIP(running.ID)
+		if err != nil {
+			log.Printf("Post-launch: failed to attach reserved public IP %s: %v", f.cfg.ReservedPublicIPID, err)
+			summary = append(summary, fmt.Sprintf("Failed to attach reserved public IP: %v", err))
+		} else {
+			log.Printf("Post-launch: attached reserved public IP %s to %s.", publicIP.IPAddress, running.DisplayName)
+			summary = append(summary, fmt.Sprintf("Reserved public IP: %s", publicIP.IPAddress))
+		}
+	}
+	summary = append(summary, f.attachBlockVolumes(running)...)
+	return strings.Join(summary, "\n")
+}
+
+// hasPostLaunchSteps reports whether any provisioning step is configured.
+func (f *finder) hasPostLaunchSteps() bool {
+	return f.cfg.ReservedPublicIPID != "" || len(f.cfg.BlockVolumes) > 0
+}
+
+// waitForInstanceRunning polls an instance until it is RUNNING.
+func (f *finder) waitForInstanceRunning(instanceID string) (*oci.Instance, error) {
+	for i := 0; i < instanceWaitAttempts; i++ {
+		instance, err := f.client.GetInstance(instanceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get instance %s: %w", instanceID, err)
+		}
+		switch instance.LifecycleState {
+		case "RUNNING":
+			return instance, nil
+		case "TERMINATING", "TERMINATED", "STOPPED":
+			return nil, fmt.Errorf("instance %s is %s", instanceID, instance.LifecycleState)
+		}
+	}
+	return nil, fmt.Errorf("instance %s did not reach RUNNING in time", instanceID)
+}
+
+// attachReservedPublicIP assigns the configured reserved public IP to the
+// primary private IP of the instance's primary VNIC.
+func (f *finder) attachReservedPublicIP(instanceID string) (*oci.PublicIP, error) {
+	vnic, err := f.primaryVnic(instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	privateIPs, err := f.client.ListPrivateIPs(vnic.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list private IPs: %w", err)
+	}
+	for _, privateIP := range privateIPs {
+		if privateIP.IsPrimary {
+			return f.client.UpdatePublicIP(f.cfg.ReservedPublicIPID, privateIP.ID)
+		}
+	}
+	return nil, fmt.Errorf("VNIC %s has no primary private IP", vnic.ID)
+}
+
+// primaryVnic returns the primary VNIC of an instance.
+func (f *finder) primaryVnic(instanceID string) (*oci.Vnic, error) {
+	attachments, err := f.client.ListVnicAttachments(instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VNIC attachments: %w", err)
+	}
+	for _, attachment := range attachments {
+		if attachment.LifecycleState != "ATTACHED" {
+			continue
+		}
+		vnic, err := f.client.GetVnic(attachment.VnicID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get VNIC %s: %w", attachment.VnicID, err)
+		}
+		if vnic.IsPrimary {
+			return vnic, nil
+		}
+	}
+	return nil, fmt.Errorf("instance %s has no attached primary VNIC", instanceID)
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// Always Free Ampere A1 allowance, shared by all A1 instances in the tenancy.
+const (
+	freeTierShape       = "VM.Standard.A1.Flex"
+	freeTierOCPUs       = 4
+	freeTierMemoryInGBs = 24
+)
+
+// Always Free block storage, shared by all boot and block volumes in the tenancy.
+const freeTierStorageGBs = 200
+
+// freeBudget is the free-tier OCPU and memory allowance not used by existing instances.
+type freeBudget struct {
+	OCPUs       float32
+	MemoryInGBs float32
+}
+
+func (b freeBudget) String() string {
+	return fmt.Sprintf("%g OCPU/%g GB", b.OCPUs, b.MemoryInGBs)
+}
+
+// fits reports whether size can be launched without exceeding the budget.
+func (b freeBudget) fits(size config.ShapeSize) bool {
+	return float32(size.OCPUs) <= b.OCPUs && float32(size.MemoryInGBs) <= b.MemoryInGBs
+}
+
+// freeTierRefreshInterval is how long the A1 instances in compartments
+// outside the cycle's own listing are cached. Launches go to the launch
+// compartment, which is listed every cycle, so only instances created by
+// other means can be missed for this long.
+const freeTierRefreshInterval = 15 * time.Minute
+
+// remainingFreeBudget computes the free-tier allowance left after the existing
+// A1 instances. The allowance is per tenancy, so every A1 instance in the home
+// region that has not been terminated is charged against it, whatever
+// compartment it is in and whichever instances count toward the target.
+// listed are the instances from the cycle's listing. Instances listed without
+// a shape configuration are fetched individually.
+func (f *finder) remainingFreeBudget(listed []oci.Instance) (freeBudget, error) {
+	instances, err := f.freeTierInstances(listed)
+	if err != nil {
+		return freeBudget{}, err
+	}
+
+	budget := freeBudget{OCPUs: freeTierOCPUs, MemoryInGBs: freeTierMemoryInGBs}
+	for _, instance := range instances {
+		if instance.LifecycleState == "TERMINATED" || instance.LifecycleState == "TERMINATING" {
+			continue
+		}
+		shapeConfig := instance.ShapeConfig
+		if shapeConfig == nil {
+			details, err := f.home.client.GetInstance(instance.ID)
+			if err != nil {
+				return freeBudget{}, fmt.Errorf("failed to get shape config of %s: %w", instance.ID, err)
+			}
+			if details.ShapeConfig == nil {
+				return freeBudget{}, fmt.Errorf("instance %s has no shape config", instance.ID)
+			}
+			shapeConfig = details.ShapeConfig
+		}
+		budget.OCPUs -= shapeConfig.Ocpus
+		budget.MemoryInGBs -= shapeConfig.MemoryInGBs
+	}
+	return budget, nil
+}
+
+// freeTierInstances returns the A1 instances in every compartment of the
+// tenancy in the home region, the only region with the Always Free allowance.
+// Compartments covered by the cycle's listing are taken from listed; the
+// others are listed at most once per freeTierRefreshInterval.
+func (f *finder) freeTierInstances(listed []oci.Instance) ([]oci.Instance, error) {
+	compartmentIDs, err := f.tenancyCompartmentIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	covered := make(map[string]bool)
+	if slices.Contains(f.regions, f.home) {
+		if f.cfg.CountSubtree {
+			for _, compartmentID := range f.compartments {
+				covered[compartmentID] = true
+			}
+		} else {
+			covered[f.cfg.LaunchCompartmentID()] = true
+		}
+	}
+
+	if f.freeTierListed.IsZero() || time.Since(f.freeTierListed) >= freeTierRefreshInterval {
+		var others []oci.Instance
+		for _, compartmentID := range compartmentIDs {
+			if covered[compartmentID] {
+				continue
+			}
+			found, err := f.home.client.ListInstancesInCompartment(compartmentID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list instances in %s: %w", compartmentID, err)
+			}
+			for _, instance := range found {
+				if instance.Shape == freeTierShape {
+					others = append(others, instance)
+				}
+			}
+		}
+		f.freeTierOthers = others
+		f.freeTierListed = time.Now()
+	}
+
+	instances := slices.Clone(f.freeTierOthers)
+	for _, instance := range listed {
+		if instance.Shape == freeTierShape && f.instanceRegions[instance.ID] == f.home && covered[instance.CompartmentID] {
+			instances = append(instances, instance)
+		}
+	}
+	return instances, nil
+}
+
+// tenancyCompartmentIDs returns the tenancy root and every compartment in
+// it. The list is fetched once.
+func (f *finder) tenancyCompartmentIDs() ([]string, error) {
+	if f.tenancyCompartments != nil {
+		return f.tenancyCompartments, nil
+	}
+	compartments, err := f.home.client.ListTenancyCompartments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenancy compartments: %w", err)
+	}
+	ids := []string{f.home.cfg.TenancyID}
+	for _, compartment := range compartments {
+		ids = append(ids, compartment.ID)
+	}
+	f.tenancyCompartments = ids
+	return ids, nil
+}
+
+// remainingFreeStorage computes the Always Free block storage left after the
+// boot and block volumes in every compartment of the home region.
+func (f *finder) remainingFreeStorage() (int64, error) {
+	compartmentIDs, err := f.tenancyCompartmentIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	home := f.home.client
+	remaining := int64(freeTierStorageGBs)
+	for _, compartmentID := range compartmentIDs {
+		bootVolumes, err := home.ListBootVolumes(compartmentID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list boot volumes in %s: %w", compartmentID, err)
+		}
+		for _, bootVolume := range bootVolumes {
+			if bootVolume.LifecycleState != "TERMINATED" && bootVolume.LifecycleState != "TERMINATING" {
+				remaining -= bootVolume.SizeInGBs
+			}
+		}
+		volumes, err := home.ListVolumes(compartmentID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list volumes in %s: %w", compartmentID, err)
+		}
+		for _, volume := range volumes {
+			if volume.LifecycleState != "TERMINATED" && volume.LifecycleState != "TERMINATING" {
+				remaining -= volume.SizeInGBs
+			}
+		}
+	}
+	return remaining, nil
+}
+
+// checkFreeStorage returns an error if creating sizeInGBs of volumes would
+// exceed the Always Free block storage, unless OCI_ALLOW_PAID is set.
+func (f *finder) checkFreeStorage(what string, sizeInGBs int64) error {
+	if f.cfg.AllowPaid {
+		return nil
+	}
+	remaining, err := f.remainingFreeStorage()
+	if err != nil {
+		return fmt.Errorf("failed to compute free block storage: %w", err)
+	}
+	if sizeInGBs > remaining {
+		return fmt.Errorf("%s needs %d GB, but only %d GB of the %d GB free block storage remains; set OCI_ALLOW_PAID=true to create it anyway", what, sizeInGBs, max(remaining, 0), freeTierStorageGBs)
+	}
+	return nil
+}
+
+// launchSizes returns the shape sizes to try given the remaining free budget.
+// Sizes that do not fit are shrunk to the budget with OCI_AUTO_SIZE, kept with
+// OCI_ALLOW_PAID, and dropped otherwise.
+func launchSizes(cfg *config.Config, budget freeBudget) []config.ShapeSize {
+	sizes := cfg.ShapeSizes()
+	if cfg.Shape != freeTierShape {
+		return sizes
+	}
+
+	var result []config.ShapeSize
+	for _, size := range sizes {
+		if !budget.fits(size) {
+			switch {
+			case cfg.AutoSize:
+				size = config.ShapeSize{
+					OCPUs:       min(size.OCPUs, int(budget.OCPUs)),
+					MemoryInGBs: min(size.MemoryInGBs, int(budget.MemoryInGBs)),
+				}
+				if size.OCPUs <= 0 || size.MemoryInGBs <= 0 {
+					continue
+				}
+			case cfg.AllowPaid:
+			default:
+				log.Printf("Skipping size %s: only %s of the free tier remains. Set OCI_ALLOW_PAID=true to launch it anyway.", size, budget)
+				continue
+			}
+		}
+		if !slices.Contains(result, size) {
+			result = append(result, size)
+		}
+	}
+	return result
+}
+
+// reservationCount returns how many instances of size to reserve for the
+// needed ones. Reserved capacity is billed, so without OCI_ALLOW_PAID the
+// free-tier shape only reserves as many instances as fit the budget.
+func reservationCount(cfg *config.Config, size config.ShapeSize, needed int, budget freeBudget) int {
+	if cfg.Shape != freeTierShape || cfg.AllowPaid {
+		return needed
+	}
+	count := 0
+	for count < needed && budget.fits(config.ShapeSize{OCPUs: (count + 1) * size.OCPUs, MemoryInGBs: (count + 1) * size.MemoryInGBs}) {
+		count++
+	}
+	if count < needed {
+		log.Printf("Reserving %d instance(s) of %s instead of %d: only %s of the free tier remains. Set OCI_ALLOW_PAID=true to reserve more.", count, size, needed, budget)
+	}
+	return count
+}
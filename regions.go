@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/idanyas/oahc-go/backoff"
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// region is one region the finder scans. Each region has its own client, and
+// so its own request pacer, as well as its own backoff and limits state,
+// because OCI rate limits and service limits are per region.
+type region struct {
+	name    string
+	cfg     *config.Config
+	client  *oci.Client
+	backoff *backoff.Manager
+	limits  limitsChecker
+
+	// resumeAt is when the region may be called again after a backoff.
+	resumeAt time.Time
+}
+
+func newRegion(cfg *config.Config, client *oci.Client) *region {
+	return &region{
+		name:    cfg.Region,
+		cfg:     cfg,
+		client:  client,
+		backoff: backoff.NewManager(cfg),
+	}
+}
+
+// scanTarget is an availability domain in a region.
+type scanTarget struct {
+	region *region
+	ad     string
+}
+
+// addRegion adds an additional region to scan.
+func (f *finder) addRegion(cfg *config.Config, client *oci.Client) {
+	f.regions = append(f.regions, newRegion(cfg, client))
+}
+
+// use directs the finder's API calls, backoff and limits checks to r.
+func (f *finder) use(r *region) {
+	f.current = r
+	f.cfg = r.cfg
+	f.client = r.client
+	f.backoff = r.backoff
+	f.limits = &r.limits
+}
+
+// backOff suspends r after a Too Many Requests or other API error. Instead of
+// sleeping, which would hold up every other region, the region's targets are
+// skipped until the backoff period has passed.
+func (f *finder) backOff(r *region) {
+	delay := r.backoff.Next()
+	r.resumeAt = time.Now().Add(delay)
+	if len(f.regions) > 1 {
+		log.Printf("Backoff activated, pausing %s for %v.", r.name, delay)
+	} else {
+		log.Printf("Backoff activated, pausing for %v.", delay)
+	}
+}
+
+// backingOff reports whether r is still in its backoff period.
+func (r *region) backingOff() bool {
+	return time.Now().Before(r.resumeAt)
+}
+
+// backoffWait returns how long until the first region comes out of its
+// backoff period, or zero if any region can be called now.
+func (f *finder) backoffWait() time.Duration {
+	var wait time.Duration
+	for i, r := range f.regions {
+		remaining := time.Until(r.resumeAt)
+		if remaining <= 0 {
+			return 0
+		}
+		if i == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+	return wait
+}
+
+// regionFor returns the region an instance was listed in.
+func (f *finder) regionFor(instanceID string) *region {
+	if r, ok := f.instanceRegions[instanceID]; ok {
+		return r
+	}
+	return f.current
+}
+
+// clientFor returns the client of the region an instance was listed in.
+func (f *finder) clientFor(instanceID string) *oci.Client {
+	return f.regionFor(instanceID).client
+}
+
+// scanSchedule returns the availability domains to try in every region,
+// interleaved so that consecutive attempts go to different regions and each
+// region's pacer has time to recover in between.
+func (f *finder) scanSchedule() ([]scanTarget, error) {
+	defer f.use(f.regions[0])
+
+	perRegion := make([][]string, len(f.regions))
+	var names []string
+	var errs []error
+	longest := 0
+	for i, r := range f.regions {
+		if r.backingOff() {
+			continue
+		}
+		f.use(r)
+		ads, err := f.getAvailabilityDomains()
+		if err != nil {
+			if len(f.regions) == 1 {
+				return nil, err
+			}
+			log.Printf("ERROR: Failed to get availability domains in %s: %v. Skipping it this cycle.", r.name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", r.name, err))
+			continue
+		}
+		// A successful API call should reset the backoff state.
+		r.backoff.Reset()
+		perRegion[i] = ads
+		names = append(names, ads...)
+		longest = max(longest, len(ads))
+	}
+	if longest == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	f.state.SetADs(names)
+
+	var schedule []scanTarget
+	for j := 0; j < longest; j++ {
+		for i, r := range f.regions {
+			if j < len(perRegion[i]) {
+				schedule = append(schedule, scanTarget{region: r, ad: perRegion[i][j]})
+			}
+		}
+	}
+	return schedule, nil
+}
+
+// homeRegionName returns the name of the tenancy's home region.
+func homeRegionName(client *oci.Client) (string, error) {
+	subscriptions, err := client.ListRegionSubscriptions()
+	if err != nil {
+		return "", fmt.Errorf("failed to list region subscriptions: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		if subscription.IsHomeRegion {
+			return subscription.RegionName, nil
+		}
+	}
+	return "", fmt.Errorf("no region subscription is marked as the home region")
+}
+
+// setHome makes the region named name the home region. A scanned region is
+// reused; otherwise client is used for it, with the primary region's settings.
+func (f *finder) setHome(name string, client func(*config.Config) *oci.Client) {
+	for _, r := range f.regions {
+		if strings.EqualFold(r.name, name) {
+			f.home = r
+			return
+		}
+	}
+	cfg := f.regions[0].cfg.ForRegion(config.RegionConfig{Region: name})
+	f.home = newRegion(cfg, client(cfg))
+}
+
+// checkRegionSubscriptions verifies that the tenancy is subscribed to every
+// configured region, and logs subscribed regions that are not scanned.
+func checkRegionSubscriptions(client *oci.Client, cfg *config.Config) error {
+	subscriptions, err := client.ListRegionSubscriptions()
+	if err != nil {
+		return fmt.Errorf("failed to list region subscriptions: %w", err)
+	}
+
+	configured := map[string]bool{strings.ToLower(cfg.Region): true}
+	for _, rc := range cfg.Regions {
+		configured[strings.ToLower(rc.Region)] = true
+	}
+
+	subscribed := make(map[string]oci.RegionSubscription)
+	var unused []string
+	for _, subscription := range subscriptions {
+		if subscription.Status != "READY" {
+			continue
+		}
+		name := strings.ToLower(subscription.RegionName)
+		subscribed[name] = subscription
+		if !configured[name] {
+			unused = append(unused, subscription.RegionName)
+		}
+	}
+
+	for name := range configured {
+		subscription, ok := subscribed[name]
+		if !ok {
+			return fmt.Errorf("the tenancy is not subscribed to region %s", name)
+		}
+		// Always Free resources only exist in the home region.
+		if !subscription.IsHomeRegion && cfg.Shape == freeTierShape && !cfg.AllowPaid {
+			return fmt.Errorf("region %s is not the home region, so %s is not free there; set OCI_ALLOW_PAID=true to scan it", name, freeTierShape)
+		}
+	}
+	if len(unused) > 0 {
+		log.Printf("Subscribed regions not in OCI_REGIONS (not scanned): %s", strings.Join(unused, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// reservationWaitAttempts bounds how many times a new reservation is polled
+// before giving up on it becoming ACTIVE. Each poll is paced by the client.
+const reservationWaitAttempts = 30
+
+// reservationCycle is the cycle body used with capacity reservations. It
+// launches from the current reservation if one has unused capacity, and in
+// OCI_CAPACITY_RESERVATION_MODE hunts for reservation capacity first.
+// budget is the remaining free-tier allowance for the free-tier shape.
+func (f *finder) reservationCycle(sizes []config.ShapeSize, needed int, budget freeBudget) bool {
+	if f.reservation == nil {
+		f.state.SetPhase("looking up capacity reservations")
+		reservation, err := f.findReservation()
+		if err != nil {
+			log.Printf("ERROR: Failed to look up capacity reservations: %v. Retrying in 30s...", err)
+			f.state.RecordError()
+			return f.state.Sleep(30 * time.Second)
+		}
+		f.reservation = reservation
+	}
+
+	if f.reservation == nil {
+		if f.cfg.CapacityReservationID != "" {
+			log.Printf("Capacity reservation %s has no unused capacity for %s. Exiting.", f.cfg.CapacityReservationID, f.cfg.Shape)
+			return false
+		}
+		return f.huntReservation(sizes, needed, budget)
+	}
+
+	return f.launchFromReservation()
+}
+
+// findReservation returns the configured reservation, or in hunting mode an
+// existing ACTIVE reservation with unused capacity for the configured shape.
+// It returns nil if there is none.
+func (f *finder) findReservation() (*oci.ComputeCapacityReservation, error) {
+	if f.cfg.CapacityReservationID != "" {
+		reservation, err := f.client.GetComputeCapacityReservation(f.cfg.CapacityReservationID)
+		if err != nil {
+			return nil, err
+		}
+		if reservation.LifecycleState != "ACTIVE" && reservation.LifecycleState != "UPDATING" {
+			return nil, fmt.Errorf("capacity reservation %s is %s", reservation.ID, reservation.LifecycleState)
+		}
+		if _, ok := unusedReservationConfig(reservation, f.cfg.Shape); !ok {
+			return nil, nil
+		}
+		return reservation, nil
+	}
+
+	summaries, err := f.client.ListComputeCapacityReservations()
+	if err != nil {
+		return nil, err
+	}
+	for _, summary := range summaries {
+		if summary.LifecycleState != "ACTIVE" || summary.UsedInstanceCount >= summary.ReservedInstanceCount {
+			continue
+		}
+		// List results do not include the reserved shapes, so fetch the details.
+		reservation, err := f.client.GetComputeCapacityReservation(summary.ID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := unusedReservationConfig(reservation, f.cfg.Shape); ok {
+			log.Printf("Using existing capacity reservation %s (%s) in %s.", reservation.DisplayName, reservation.ID, reservation.AvailabilityDomain)
+			return reservation, nil
+		}
+	}
+	return nil, nil
+}
+
+// huntReservation tries to create a capacity reservation for the missing
+// instances in each availability domain, stepping down through sizes.
+// Without OCI_ALLOW_PAID, free-tier reservations are kept within budget.
+func (f *finder) huntReservation(sizes []config.ShapeSize, needed int, budget freeBudget) bool {
+	f.state.SetPhase("resolving availability domains")
+	availabilityDomains, err := f.getAvailabilityDomains()
+	if err != nil {
+		log.Printf("ERROR: Failed to get availability domains: %v. Retrying in 30s...", err)
+		f.state.RecordError()
+		return f.state.Sleep(30 * time.Second)
+	}
+	f.state.SetADs(availabilityDomains)
+
+	for _, ad := range availabilityDomains {
+		if f.state.Paused() {
+			break
+		}
+		select {
+		case <-f.state.Stopped():
+			log.Println("Stop requested. Exiting.")
+			return false
+		default:
+		}
+
+		for _, size := range sizes {
+			count := reservationCount(f.cfg, size, needed, budget)
+			if count == 0 {
+				continue
+			}
+			label := fmt.Sprintf("%s (%s x%d)", ad, size, count)
+			f.state.SetPhase(fmt.Sprintf("reserving capacity in %s", label))
+			reservation, err := f.client.CreateComputeCapacityReservation(ad, shapeConfigOf(size), count)
+			if err != nil {
+				if isTooManyRequests(err) {
+					log.Printf("Reserving %s: Too Many Requests.", label)
+					f.state.RecordAttempt(ad, "too many requests")
+					f.state.RecordTooManyRequests()
+					f.backOff(f.current)
+					return true
+				}
+				if isOutOfCapacity(err) {
+					log.Printf("Reserving %s: Out of capacity.", label)
+					f.state.RecordAttempt(ad, fmt.Sprintf("reservation out of capacity (%s)", size))
+					f.state.RecordOutOfCapacity()
+					f.backoff.Reset()
+					continue
+				}
+				log.Printf("Reserving %s: Unrecoverable API Error: %v", label, err)
+				f.state.RecordAttempt(ad, "error")
+				f.state.RecordError()
+				f.backOff(f.current)
+				return true
+			}
+
+			reservation, err = f.waitForReservation(reservation)
+			if err != nil {
+				log.Printf("Reserving %s: %v", label, err)
+				f.state.RecordAttempt(ad, fmt.Sprintf("reservation failed (%s)", size))
+				continue
+			}
+
+			log.Printf("Reserving %s: Success! Capacity reservation created.", label)
+			f.state.RecordAttempt(ad, fmt.Sprintf("reserved (%s x%d)", size, count))
+			prettyDetails, _ := json.MarshalIndent(reservation, "", "  ")
+			log.Printf("Successfully created capacity reservation!\n%s", string(prettyDetails))
+			f.notify(string(prettyDetails))
+
+			f.backoff.Reset()
+			f.reservation = reservation
+			return f.launchFromReservation()
+		}
+	}
+
+	f.backoff.Reset()
+	return true
+}
+
+// waitForReservation polls a new reservation until it becomes ACTIVE.
+func (f *finder) waitForReservation(reservation *oci.ComputeCapacityReservation) (*oci.ComputeCapacityReservation, error) {
+	for i := 0; reservation.LifecycleState != "ACTIVE"; i++ {
+		switch reservation.LifecycleState {
+		case "FAILED", "DELETED", "DELETING":
+			return nil, fmt.Errorf("capacity reservation %s is %s", reservation.ID, reservation.LifecycleState)
+		}
+		if i == reservationWaitAttempts {
+			return nil, fmt.Errorf("capacity reservation %s did not become ACTIVE (still %s)", reservation.ID, reservation.LifecycleState)
+		}
+
+		var err error
+		if reservation, err = f.client.GetComputeCapacityReservation(reservation.ID); err != nil {
+			return nil, fmt.Errorf("failed to get capacity reservation: %w", err)
+		}
+	}
+	return reservation, nil
+}
+
+// launchFromReservation launches one instance into the current reservation.
+func (f *finder) launchFromReservation() bool {
+	reservation := f.reservation
+	reservationConfig, ok := unusedReservationConfig(reservation, f.cfg.Shape)
+	if !ok || reservationConfig.InstanceShapeConfig == nil {
+		log.Printf("Capacity reservation %s has no unused capacity left.", reservation.ID)
+		f.reservation = nil
+		return true
+	}
+
+	shapeConfig := *reservationConfig.InstanceShapeConfig
+	size := config.ShapeSize{OCPUs: int(shapeConfig.Ocpus), MemoryInGBs: int(shapeConfig.MemoryInGBs)}
+	label := fmt.Sprintf("%s (reservation %s)", reservation.AvailabilityDomain, reservation.DisplayName)
+
+	outcome := f.attemptLaunch(label, size, oci.LaunchParams{
+		AvailabilityDomain:    reservation.AvailabilityDomain,
+		ShapeConfig:           shapeConfig,
+		CapacityReservationID: reservation.ID,
+	})
+	switch outcome {
+	case launchCreated:
+		// The next cycle re-lists instances and stops once the target is reached.
+		reservationConfig.UsedCount++
+		reservation.UsedInstanceCount++
+	case launchOutOfCapacity:
+		// The reservation is exhausted or was changed; look it up again next cycle.
+		f.reservation = nil
+	}
+	return true
+}
+
+// unusedReservationConfig returns the reservation config for shape that still has unused capacity.
+func unusedReservationConfig(reservation *oci.ComputeCapacityReservation, shape string) (*oci.InstanceReservationConfig, bool) {
+	for i := range reservation.InstanceReservationConfigs {
+		rc := &reservation.InstanceReservationConfigs[i]
+		if rc.InstanceShape == shape && rc.UsedCount < rc.ReservedCount {
+			return rc, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// bootVolumeWaitAttempts bounds how many times a restored boot volume is
+// polled while waiting for it to become AVAILABLE. Each poll is paced by the client.
+const bootVolumeWaitAttempts = 45
+
+// restoredBootVolume returns a boot volume restored from
+// OCI_BOOT_VOLUME_BACKUP_ID in ad, restoring one first if needed. Boot volumes
+// are bound to an availability domain, so each domain that is tried gets its
+// own copy, which is reused by later attempts there.
+func (f *finder) restoredBootVolume(ad string) (string, error) {
+	if id, ok := f.restored[ad]; ok {
+		return id, nil
+	}
+
+	f.state.SetPhase(fmt.Sprintf("restoring boot volume in %s", ad))
+	sizeInGBs := int64(f.cfg.BootVolumeSizeGbs)
+	if sizeInGBs == 0 {
+		backup, err := f.client.GetBootVolumeBackup(f.cfg.BootVolumeBackupID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get boot volume backup: %w", err)
+		}
+		sizeInGBs = backup.SizeInGBs
+	}
+	if err := f.checkFreeStorage("restoring the boot volume backup", sizeInGBs); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("restored-%s", time.Now().Format("20060102-1504"))
+	bootVolume, err := f.client.CreateBootVolumeFromBackup(ad, f.cfg.BootVolumeBackupID, name, f.cfg.BootVolumeSizeGbs)
+	if err != nil {
+		return "", fmt.Errorf("failed to restore boot volume backup: %w", err)
+	}
+	log.Printf("Restoring boot volume backup %s into %s as %s (%s).", f.cfg.BootVolumeBackupID, ad, bootVolume.DisplayName, bootVolume.ID)
+
+	for i := 0; bootVolume.LifecycleState != "AVAILABLE"; i++ {
+		if bootVolume.LifecycleState == "FAULTY" || bootVolume.LifecycleState == "TERMINATED" {
+			return "", fmt.Errorf("restored boot volume %s is %s", bootVolume.ID, bootVolume.LifecycleState)
+		}
+		if i == bootVolumeWaitAttempts {
+			return "", fmt.Errorf("restored boot volume %s did not become AVAILABLE (still %s)", bootVolume.ID, bootVolume.LifecycleState)
+		}
+		if bootVolume, err = f.client.GetBootVolume(bootVolume.ID); err != nil {
+			return "", fmt.Errorf("failed to get restored boot volume: %w", err)
+		}
+	}
+
+	f.restored[ad] = bootVolume.ID
+	return bootVolume.ID, nil
+}
+
+// cleanupRestoredBootVolumes deletes restored boot volumes that were not used
+// by a launch. It runs whenever the finder exits; each volume is logged first
+// so that it can be deleted by hand if the process is killed meanwhile.
+func (f *finder) cleanupRestoredBootVolumes() {
+	for ad, id := range f.restored {
+		log.Printf("Deleting unused restored boot volume %s in %s.", id, ad)
+	}
+	for ad, id := range f.restored {
+		if err := f.client.DeleteBootVolume(id); err != nil {
+			log.Printf("Warning: could not delete unused restored boot volume %s in %s: %v", id, ad, err)
+			continue
+		}
+		delete(f.restored, ad)
+	}
+}
@@ -0,0 +1,90 @@
+package state
+
+import (
+	"sync"
+	"time"
+)
+
+// Target is the state of one named target.
+type Target struct {
+	Name  string
+	State *State
+}
+
+// Group is the set of targets run by one process, viewed and controlled
+// together by front ends such as the Telegram bot.
+type Group struct {
+	mu       sync.Mutex
+	targets  []Target
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewGroup creates an empty group.
+func NewGroup() *Group {
+	return &Group{stopCh: make(chan struct{})}
+}
+
+// Add adds a target's state to the group.
+func (g *Group) Add(name string, st *State) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.targets = append(g.targets, Target{Name: name, State: st})
+}
+
+// Targets returns the targets in the order they were added.
+func (g *Group) Targets() []Target {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]Target(nil), g.targets...)
+}
+
+// Get returns the state of the named target.
+func (g *Group) Get(name string) (*State, bool) {
+	for _, target := range g.Targets() {
+		if target.Name == name {
+			return target.State, true
+		}
+	}
+	return nil, false
+}
+
+// Stop asks every target to exit.
+func (g *Group) Stop() {
+	for _, target := range g.Targets() {
+		target.State.Stop()
+	}
+	g.stopOnce.Do(func() { close(g.stopCh) })
+}
+
+// Stopped returns a channel that is closed once Stop has been called.
+func (g *Group) Stopped() <-chan struct{} {
+	return g.stopCh
+}
+
+// Sleep waits for d, returning early with false if a stop was requested.
+func (g *Group) Sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-g.stopCh:
+		return false
+	}
+}
+
+// Totals returns the counters of all targets added together.
+func (g *Group) Totals() Stats {
+	var total Stats
+	for _, target := range g.Targets() {
+		stats := target.State.Snapshot().Stats
+		total.Cycles += stats.Cycles
+		total.Attempts += stats.Attempts
+		total.OutOfCapacity += stats.OutOfCapacity
+		total.TooManyReqs += stats.TooManyReqs
+		total.Errors += stats.Errors
+		total.Created += stats.Created
+	}
+	return total
+}
@@ -0,0 +1,246 @@
+package state
+
+import (
+	"sync"
+	"time"
+
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// ADResult records the outcome of the most recent launch attempt in an availability domain.
+type ADResult struct {
+	Name    string
+	Result  string
+	Checked time.Time
+}
+
+// Stats holds the counters accumulated by the main loop.
+type Stats struct {
+	Cycles        int
+	Attempts      int
+	OutOfCapacity int
+	TooManyReqs   int
+	Errors        int
+	Created       int
+}
+
+// Snapshot is a point-in-time copy of the shared state, safe to read without locking.
+type Snapshot struct {
+	Started           time.Time
+	Paused            bool
+	Phase             string
+	ExistingInstances int
+	MaxInstances      int
+	LastCycle         time.Time
+	Stats             Stats
+	ADs               []ADResult
+	Instances         []oci.Instance
+}
+
+// State is shared between the main loop and remote-control front ends such as the Telegram bot.
+type State struct {
+	mu       sync.Mutex
+	started  time.Time
+	paused   bool
+	resumeCh chan struct{}
+	stopCh   chan struct{}
+	stopOnce sync.Once
+
+	phase             string
+	existingInstances int
+	maxInstances      int
+	lastCycle         time.Time
+	stats             Stats
+	adOrder           []string
+	ads               map[string]ADResult
+	instances         []oci.Instance
+}
+
+// New creates a new State for a finder targeting maxInstances instances.
+func New(maxInstances int) *State {
+	return &State{
+		started:      time.Now(),
+		resumeCh:     make(chan struct{}),
+		stopCh:       make(chan struct{}),
+		phase:        "starting",
+		maxInstances: maxInstances,
+		ads:          make(map[string]ADResult),
+	}
+}
+
+// Pause asks the main loop to stop issuing launch attempts. It returns false if already paused.
+func (s *State) Pause() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused {
+		return false
+	}
+	s.paused = true
+	return true
+}
+
+// Resume lets a paused main loop continue. It returns false if the loop was not paused.
+func (s *State) Resume() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.paused {
+		return false
+	}
+	s.paused = false
+	close(s.resumeCh)
+	s.resumeCh = make(chan struct{})
+	return true
+}
+
+// Paused reports whether the main loop is currently paused.
+func (s *State) Paused() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused
+}
+
+// Stop asks the main loop to exit at the next opportunity.
+func (s *State) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
+
+// Stopped returns a channel that is closed once Stop has been called.
+func (s *State) Stopped() <-chan struct{} {
+	return s.stopCh
+}
+
+// WaitWhilePaused blocks while the loop is paused. It returns false if a stop was requested.
+func (s *State) WaitWhilePaused() bool {
+	for {
+		s.mu.Lock()
+		paused, resumeCh := s.paused, s.resumeCh
+		s.mu.Unlock()
+
+		if !paused {
+			select {
+			case <-s.stopCh:
+				return false
+			default:
+				return true
+			}
+		}
+
+		select {
+		case <-resumeCh:
+		case <-s.stopCh:
+			return false
+		}
+	}
+}
+
+// Sleep waits for d, returning early with false if a stop was requested.
+func (s *State) Sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-s.stopCh:
+		return false
+	}
+}
+
+// SetPhase records a short human-readable description of what the main loop is doing.
+func (s *State) SetPhase(phase string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.phase = phase
+}
+
+// StartCycle marks the beginning of a new scan cycle.
+func (s *State) StartCycle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Cycles++
+	s.lastCycle = time.Now()
+}
+
+// SetInstances stores the latest instance listing and the number counted against the target.
+func (s *State) SetInstances(instances []oci.Instance, existing int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.instances = append([]oci.Instance(nil), instances...)
+	s.existingInstances = existing
+}
+
+// SetADs stores the availability domains being scanned, keeping previous results for known ADs.
+func (s *State) SetADs(ads []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.adOrder = append([]string(nil), ads...)
+}
+
+// RecordAttempt records the outcome of a launch attempt in an availability domain.
+func (s *State) RecordAttempt(ad, result string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Attempts++
+	s.ads[ad] = ADResult{Name: ad, Result: result, Checked: time.Now()}
+}
+
+// RecordSkipped records that an availability domain was skipped without a launch attempt.
+func (s *State) RecordSkipped(ad, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ads[ad] = ADResult{Name: ad, Result: "skipped: " + reason, Checked: time.Now()}
+}
+
+// RecordOutOfCapacity increments the out-of-capacity counter.
+func (s *State) RecordOutOfCapacity() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.OutOfCapacity++
+}
+
+// RecordTooManyRequests increments the rate-limit counter.
+func (s *State) RecordTooManyRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.TooManyReqs++
+}
+
+// RecordError increments the generic error counter.
+func (s *State) RecordError() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Errors++
+}
+
+// RecordCreated increments the created-instances counter.
+func (s *State) RecordCreated() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Created++
+}
+
+// Snapshot returns a copy of the current state.
+func (s *State) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ads := make([]ADResult, 0, len(s.adOrder))
+	for _, name := range s.adOrder {
+		if res, ok := s.ads[name]; ok {
+			ads = append(ads, res)
+		} else {
+			ads = append(ads, ADResult{Name: name})
+		}
+	}
+
+	return Snapshot{
+		Started:           s.started,
+		Paused:            s.paused,
+		Phase:             s.phase,
+		ExistingInstances: s.existingInstances,
+		MaxInstances:      s.maxInstances,
+		LastCycle:         s.lastCycle,
+		Stats:             s.stats,
+		ADs:               ads,
+		Instances:         append([]oci.Instance(nil), s.instances...),
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// attachBlockVolumes creates or looks up each configured block volume in the
+// instance's availability domain and attaches it. It returns one summary line
+// per volume for the success notification.
+func (f *finder) attachBlockVolumes(instance *oci.Instance) []string {
+	var summary []string
+	for i, spec := range f.cfg.BlockVolumes {
+		f.state.SetPhase(fmt.Sprintf("attaching block volume %d to %s", i+1, instance.DisplayName))
+		attachment, volume, err := f.attachBlockVolume(instance, i, spec)
+		if err != nil {
+			log.Printf("Post-launch: block volume %d: %v", i+1, err)
+			summary = append(summary, fmt.Sprintf("Failed to attach block volume %d: %v", i+1, err))
+			continue
+		}
+
+		line := fmt.Sprintf("Block volume %s (%d GB): %s", volume.DisplayName, volume.SizeInGBs, attachment.AttachmentType)
+		if attachment.Device != "" {
+			line += fmt.Sprintf(" at %s", attachment.Device)
+		}
+		if attachment.AttachmentType == config.AttachmentTypeISCSI {
+			line += fmt.Sprintf(", iSCSI target %s on %s:%d", attachment.Iqn, attachment.Ipv4, attachment.Port)
+		}
+		log.Printf("Post-launch: attached block volume %s (%s) to %s.", volume.DisplayName, volume.ID, instance.DisplayName)
+		summary = append(summary, line)
+	}
+	return summary
+}
+
+// attachBlockVolume provides one block volume and attaches it to the instance.
+func (f *finder) attachBlockVolume(instance *oci.Instance, index int, spec config.BlockVolume) (*oci.VolumeAttachment, *oci.Volume, error) {
+	var volume *oci.Volume
+	var err error
+	if spec.VolumeID != "" {
+		if volume, err = f.client.GetVolume(spec.VolumeID); err != nil {
+			return nil, nil, fmt.Errorf("failed to get volume %s: %w", spec.VolumeID, err)
+		}
+		if volume.AvailabilityDomain != instance.AvailabilityDomain {
+			return nil, nil, fmt.Errorf("volume %s is in %s, but the instance is in %s", volume.ID, volume.AvailabilityDomain, instance.AvailabilityDomain)
+		}
+	} else {
+		if volume, err = f.createBlockVolume(instance, index, spec); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	attachment, workRequestID, err := f.client.AttachVolume(oci.AttachVolumeDetails{
+		Type:       spec.AttachmentType,
+		InstanceID: instance.ID,
+		VolumeID:   volume.ID,
+		IsReadOnly: spec.ReadOnly,
+	})
+	if err != nil {
+		return nil, volume, fmt.Errorf("failed to attach volume %s: %w", volume.ID, err)
+	}
+	if workRequestID != "" {
+		if _, err := f.client.WaitForWorkRequest(workRequestID); err != nil {
+			return nil, volume, err
+		}
+	}
+
+	for i := 0; attachment.LifecycleState != "ATTACHED"; i++ {
+		if attachment.LifecycleState == "DETACHING" || attachment.LifecycleState == "DETACHED" {
+			return nil, volume, fmt.Errorf("volume attachment %s is %s", attachment.ID, attachment.LifecycleState)
+		}
+		if i == instanceWaitAttempts {
+			return nil, volume, fmt.Errorf("volume attachment %s did not become ATTACHED (still %s)", attachment.ID, attachment.LifecycleState)
+		}
+		if attachment, err = f.client.GetVolumeAttachment(attachment.ID); err != nil {
+			return nil, volume, fmt.Errorf("failed to get volume attachment: %w", err)
+		}
+	}
+	return attachment, volume, nil
+}
+
+// createBlockVolume creates a block volume in the instance's availability
+// domain and waits for it to become AVAILABLE. The volume gets the same tags
+// as instances, rendered for the volume.
+func (f *finder) createBlockVolume(instance *oci.Instance, index int, spec config.BlockVolume) (*oci.Volume, error) {
+	if err := f.checkFreeStorage(fmt.Sprintf("block volume %d", index+1), int64(spec.SizeInGBs)); err != nil {
+		return nil, err
+	}
+
+	details := oci.CreateVolumeDetails{
+		AvailabilityDomain: instance.AvailabilityDomain,
+		DisplayName:        spec.DisplayName,
+		SizeInGBs:          int64(spec.SizeInGBs),
+	}
+	if details.DisplayName == "" {
+		details.DisplayName = fmt.Sprintf("%s-volume-%d", instance.DisplayName, index+1)
+	}
+	if spec.VpusPerGB != nil {
+		vpus := int64(*spec.VpusPerGB)
+		details.VpusPerGB = &vpus
+	}
+	hostname, _ := os.Hostname()
+	freeformTags, definedTags, err := f.cfg.InstanceTags(config.TagVars{
+		LaunchTime:         time.Now().UTC().Format(time.RFC3339),
+		AvailabilityDomain: instance.AvailabilityDomain,
+		DisplayName:        details.DisplayName,
+		Shape:              f.cfg.Shape,
+		Hostname:           hostname,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render tags: %w", err)
+	}
+	details.FreeformTags, details.DefinedTags = freeformTags, definedTags
+
+	volume, err := f.client.CreateVolume(details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create volume: %w", err)
+	}
+	for i := 0; volume.LifecycleState != "AVAILABLE"; i++ {
+		if volume.LifecycleState == "FAULTY" || volume.LifecycleState == "TERMINATED" {
+			return nil, fmt.Errorf("volume %s is %s", volume.ID, volume.LifecycleState)
+		}
+		if i == instanceWaitAttempts {
+			return nil, fmt.Errorf("volume %s did not become AVAILABLE (still %s)", volume.ID, volume.LifecycleState)
+		}
+		if volume, err = f.client.GetVolume(volume.ID); err != nil {
+			return nil, fmt.Errorf("failed to get volume: %w", err)
+		}
+	}
+	return volume, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/idanyas/oahc-go/config"
+	"github.com/idanyas/oahc-go/oci"
+)
+
+// countsTowardTarget reports whether an instance counts against MaxInstances.
+// With OCI_COUNT_TAG, only instances carrying that freeform tag count.
+// In watch mode, stopped instances count as lost so that capacity hunting resumes.
+func countsTowardTarget(instance oci.Instance, cfg *config.Config) bool {
+	if instance.Shape != cfg.Shape {
+		return false
+	}
+	if cfg.CountTagKey != "" {
+		if val, ok := instance.FreeformTags[cfg.CountTagKey]; !ok || val != cfg.CountTagValue {
+			return false
+		}
+	}
+	switch instance.LifecycleState {
+	case "TERMINATED":
+		return false
+	case "TERMINATING", "STOPPING", "STOPPED":
+		return !cfg.WatchMode
+	}
+	return true
+}
+
+// instanceWatcher remembers the instances counted in the previous listing so
+// that reclaimed or terminated ones can be reported.
+type instanceWatcher struct {
+	known map[string]oci.Instance
+}
+
+func newInstanceWatcher() *instanceWatcher {
+	return &instanceWatcher{known: make(map[string]oci.Instance)}
+}
+
+// update records the current listing and returns the previously counted
+// instances that have since disappeared or stopped counting toward the target.
+func (w *instanceWatcher) update(instances []oci.Instance, cfg *config.Config) []oci.Instance {
+	current := make(map[string]oci.Instance)
+	byID := make(map[string]oci.Instance, len(instances))
+	for _, instance := range instances {
+		byID[instance.ID] = instance
+		if countsTowardTarget(instance, cfg) {
+			current[instance.ID] = instance
+		}
+	}
+
+	var lost []oci.Instance
+	for id, previous := range w.known {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		if instance, ok := byID[id]; ok {
+			lost = append(lost, instance)
+		} else {
+			previous.LifecycleState = "MISSING"
+			lost = append(lost, previous)
+		}
+	}
+
+	w.known = current
+	return lost
+}
+
+// lostInstancesMessage formats a notification about lost instances. It only
+// announces that hunting resumes when the remaining count is below the target.
+func lostInstancesMessage(lost []oci.Instance, remaining, target int) string {
+	var sb strings.Builder
+	if remaining < target {
+		sb.WriteString("Instance(s) lost, resuming capacity hunting:")
+	} else {
+		fmt.Fprintf(&sb, "Instance(s) lost, %d of %d target instance(s) remain:", remaining, target)
+	}
+	for _, instance := range lost {
+		fmt.Fprintf(&sb, "\n- %s (%s): %s", instance.DisplayName, instance.ID, instance.LifecycleState)
+	}
+	return sb.String()
+}